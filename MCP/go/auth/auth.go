@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/neutrino-api/mcp-server/config"
+)
+
+// Principal is an authenticated MCP client
+type Principal struct {
+	Subject string   // Client identity, e.g. the token's principal or the JWT sub claim
+	Tenant  string   // Tenant whose configuration the client uses
+	Method  string   // Authentication method that accepted the client
+	Scopes  []string // Granted scopes (JWT only)
+}
+
+var (
+	// ErrNoToken means the request carried no bearer token
+	ErrNoToken = errors.New("missing bearer token")
+	// ErrInvalidToken means the token was malformed, expired or not recognised
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrInsufficientScope means the token lacks a required scope
+	ErrInsufficientScope = errors.New("insufficient scope")
+)
+
+// Method verifies one kind of bearer token
+type Method interface {
+	Name() string
+	// Authenticate returns the token's principal or an error wrapping ErrInvalidToken
+	Authenticate(token string) (*Principal, error)
+}
+
+// Authenticator checks the bearer token of inbound MCP requests against the
+// configured methods, in order
+type Authenticator struct {
+	methods   []Method
+	tenantMap map[string]string
+	resource  *ResourceMetadata
+}
+
+// New creates an Authenticator for the methods enabled in cfg
+func New(cfg *config.AuthConfig) (*Authenticator, error) {
+	a := &Authenticator{tenantMap: cfg.TenantMap}
+	for _, name := range cfg.Methods {
+		switch name {
+		case "static":
+			a.methods = append(a.methods, newStaticMethod(cfg.StaticTokens))
+		case "hmac":
+			a.methods = append(a.methods, newHMACMethod(cfg.HMACSecret))
+		case "jwt":
+			m, err := newJWTMethod(cfg)
+			if err != nil {
+				return nil, err
+			}
+			a.methods = append(a.methods, m)
+		default:
+			return nil, fmt.Errorf("unknown authentication method %q", name)
+		}
+	}
+	if cfg.ResourceURL != "" {
+		a.resource = &ResourceMetadata{
+			Resource:               cfg.ResourceURL,
+			AuthorizationServers:   cfg.AuthorizationServers,
+			ScopesSupported:        cfg.RequiredScopes,
+			BearerMethodsSupported: []string{"header"},
+		}
+	}
+	return a, nil
+}
+
+// Authenticate resolves the principal for a bearer token
+func (a *Authenticator) Authenticate(token string) (*Principal, error) {
+	if token == "" {
+		return nil, ErrNoToken
+	}
+	err := ErrInvalidToken
+	for _, m := range a.methods {
+		p, mErr := m.Authenticate(token)
+		if mErr == nil {
+			p.Method = m.Name()
+			if p.Tenant == "" {
+				p.Tenant = a.tenantMap[p.Subject]
+			}
+			if p.Tenant == "" {
+				p.Tenant = p.Subject
+			}
+			return p, nil
+		}
+		if errors.Is(mErr, ErrInsufficientScope) {
+			// The token is genuine, report the more useful error
+			err = mErr
+		}
+	}
+	return nil, err
+}
+
+// Middleware rejects requests without a valid bearer token and attaches the
+// principal to the request context of the ones it lets through
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, err := a.Authenticate(bearerToken(r))
+		if err != nil {
+			log.Printf("Rejected MCP request from %s: %v", r.RemoteAddr, err)
+			a.challenge(w, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), p)))
+	})
+}
+
+// challenge writes the 401/403 response including the WWW-Authenticate
+// header that points OAuth clients at the resource metadata
+func (a *Authenticator) challenge(w http.ResponseWriter, err error) {
+	params := []string{`realm="mcp"`}
+	if a.resource != nil {
+		params = append(params, fmt.Sprintf("resource_metadata=%q", a.resource.metadataURL()))
+	}
+	status := http.StatusUnauthorized
+	switch {
+	case errors.Is(err, ErrInsufficientScope):
+		status = http.StatusForbidden
+		params = append(params, `error="insufficient_scope"`)
+		if a.resource != nil && len(a.resource.ScopesSupported) > 0 {
+			params = append(params, fmt.Sprintf("scope=%q", strings.Join(a.resource.ScopesSupported, " ")))
+		}
+	case errors.Is(err, ErrInvalidToken):
+		params = append(params, `error="invalid_token"`)
+	}
+	w.Header().Set("WWW-Authenticate", "Bearer "+strings.Join(params, ", "))
+	http.Error(w, http.StatusText(status), status)
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer" header
+func bearerToken(r *http.Request) string {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+type contextKey struct{}
+
+// WithPrincipal returns a context carrying the authenticated principal
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// PrincipalFromContext returns the principal attached by Middleware, if any
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(contextKey{}).(*Principal)
+	return p, ok && p != nil
+}
@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStaticMethod(t *testing.T) {
+	m := newStaticMethod(map[string]string{"token-a": "alice", "token-b": "bob"})
+	p, err := m.Authenticate("token-b")
+	if err != nil || p.Subject != "bob" {
+		t.Errorf("Authenticate(token-b) = %+v, %v", p, err)
+	}
+	for _, token := range []string{"token-c", "token-", "TOKEN-A", ""} {
+		if _, err := m.Authenticate(token); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Authenticate(%q) err = %v, want %v", token, err, ErrInvalidToken)
+		}
+	}
+}
+
+// newTestAuthenticator accepts static, HMAC and JWT tokens
+func newTestAuthenticator(t *testing.T) *Authenticator {
+	t.Helper()
+	cfg := testJWTConfig(t)
+	cfg.Methods = []string{"static", "hmac", "jwt"}
+	cfg.StaticTokens = map[string]string{"static-token": "alice"}
+	cfg.HMACSecret = testHMACSecret
+	cfg.TenantMap = map[string]string{"alice": "acme"}
+	a, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range a.methods {
+		switch m := m.(type) {
+		case *jwtMethod:
+			m.now = func() time.Time { return testNow }
+		case *hmacMethod:
+			m.now = func() time.Time { return testNow }
+		}
+	}
+	return a
+}
+
+func TestAuthenticatorResolvesTenant(t *testing.T) {
+	a := newTestAuthenticator(t)
+	for _, tc := range []struct {
+		token  string
+		method string
+		tenant string
+	}{
+		{"static-token", "static", "acme"}, // From the tenant map
+		{signHMAC(t, testHMACSecret, HMACClaims{Subject: "bob"}), "hmac", "bob"},
+		{signHMAC(t, testHMACSecret, HMACClaims{Subject: "bob", Tenant: "globex"}), "hmac", "globex"},
+		{signJWT(t, "RS256", "rsa", testRSAKey, validClaims()), "jwt", "acme"},
+	} {
+		p, err := a.Authenticate(tc.token)
+		if err != nil {
+			t.Errorf("%s: %v", tc.method, err)
+			continue
+		}
+		if p.Method != tc.method || p.Tenant != tc.tenant {
+			t.Errorf("%s: principal = %+v, want tenant %s", tc.method, p, tc.tenant)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	a := newTestAuthenticator(t)
+	handler := a.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, ok := PrincipalFromContext(r.Context())
+		if !ok {
+			t.Error("no principal in the request context")
+			return
+		}
+		w.Write([]byte(p.Subject))
+	}))
+
+	for _, tc := range []struct {
+		name      string
+		header    string
+		status    int
+		challenge string // Expected in WWW-Authenticate
+		body      string
+	}{
+		{"no token", "", http.StatusUnauthorized, `Bearer realm="mcp", resource_metadata="https://mcp.example.com/.well-known/oauth-protected-resource"`, ""},
+		{"not bearer", "Basic c3RhdGljLXRva2Vu", http.StatusUnauthorized, `realm="mcp"`, ""},
+		{"invalid token", "Bearer nope", http.StatusUnauthorized, `error="invalid_token"`, ""},
+		{"expired JWT", "Bearer " + signJWT(t, "RS256", "rsa", testRSAKey, with(validClaims(), "exp", testNow.Add(-time.Hour).Unix())), http.StatusUnauthorized, `error="invalid_token"`, ""},
+		{"missing scope", "Bearer " + signJWT(t, "RS256", "rsa", testRSAKey, with(validClaims(), "scope", "other")), http.StatusForbidden, `error="insufficient_scope", scope="mcp:tools"`, ""},
+		{"static", "Bearer static-token", http.StatusOK, "", "alice"},
+		{"lower case scheme", "bearer static-token", http.StatusOK, "", "alice"},
+		{"JWT", "Bearer " + signJWT(t, "ES256", "ec", testECKey, validClaims()), http.StatusOK, "", "client-1"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tc.status {
+				t.Errorf("status = %d, want %d", rec.Code, tc.status)
+			}
+			if got := rec.Header().Get("WWW-Authenticate"); !strings.Contains(got, tc.challenge) {
+				t.Errorf("WWW-Authenticate = %q, want it to contain %q", got, tc.challenge)
+			}
+			if tc.body != "" && rec.Body.String() != tc.body {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tc.body)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testHMACSecret = "0123456789abcdef0123456789abcdef"
+
+func newTestHMACMethod() *hmacMethod {
+	m := newHMACMethod(testHMACSecret)
+	m.now = func() time.Time { return testNow }
+	return m
+}
+
+func signHMAC(t *testing.T, secret string, claims HMACClaims) string {
+	t.Helper()
+	token, err := SignHMACToken(secret, claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestHMACAccepts(t *testing.T) {
+	m := newTestHMACMethod()
+	for name, claims := range map[string]HMACClaims{
+		"expiring":      {Subject: "client-1", Tenant: "acme", ExpiresAt: testNow.Add(time.Minute).Unix()},
+		"never expires": {Subject: "client-1", Tenant: "acme"},
+	} {
+		p, err := m.Authenticate(signHMAC(t, testHMACSecret, claims))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if p.Subject != "client-1" || p.Tenant != "acme" {
+			t.Errorf("%s: principal = %+v", name, p)
+		}
+	}
+}
+
+func TestHMACRejects(t *testing.T) {
+	m := newTestHMACMethod()
+	valid := signHMAC(t, testHMACSecret, HMACClaims{Subject: "client-1"})
+	i := strings.LastIndexByte(valid, '.')
+	forged := signHMAC(t, testHMACSecret, HMACClaims{Subject: "admin"})
+
+	for name, token := range map[string]string{
+		"expired":          signHMAC(t, testHMACSecret, HMACClaims{Subject: "client-1", ExpiresAt: testNow.Unix()}),
+		"other secret":     signHMAC(t, strings.Repeat("x", 32), HMACClaims{Subject: "client-1"}),
+		"tampered payload": forged[:strings.LastIndexByte(forged, '.')] + valid[i:],
+		"bad signature":    valid[:i+1] + b64([]byte("not the signature")),
+		"no signature":     valid[:i],
+		"no subject":       signHMAC(t, testHMACSecret, HMACClaims{Tenant: "acme"}),
+		"other version":    "v2" + strings.TrimPrefix(valid, "v1"),
+		"JWT":              "eyJhbGciOiJub25lIn0.e30.",
+	} {
+		if _, err := m.Authenticate(token); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s: err = %v, want %v", name, err, ErrInvalidToken)
+		}
+	}
+}
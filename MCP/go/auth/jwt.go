@@ -0,0 +1,321 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/neutrino-api/mcp-server/config"
+)
+
+// jwtMethod validates OAuth 2.1 access tokens issued as JWTs by an external
+// authorization server, using signing keys from a local JWKS file
+type jwtMethod struct {
+	keys           map[string]crypto.PublicKey // kid -> key
+	issuer         string
+	audience       string
+	tenantClaim    string
+	leeway         time.Duration
+	requiredScopes []string
+	now            func() time.Time
+}
+
+func newJWTMethod(cfg *config.AuthConfig) (*jwtMethod, error) {
+	keys, err := loadJWKS(cfg.JWKSFile)
+	if err != nil {
+		return nil, err
+	}
+	return &jwtMethod{
+		keys:           keys,
+		issuer:         cfg.JWTIssuer,
+		audience:       cfg.JWTAudience,
+		tenantClaim:    cfg.JWTTenantClaim,
+		leeway:         cfg.JWTLeeway,
+		requiredScopes: cfg.RequiredScopes,
+		now:            time.Now,
+	}, nil
+}
+
+func (m *jwtMethod) Name() string {
+	return "jwt"
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+func (m *jwtMethod) Authenticate(token string) (*Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: not a JWT", ErrInvalidToken)
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: malformed JWT header", ErrInvalidToken)
+	}
+	key, err := m.key(header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed JWT signature", ErrInvalidToken)
+	}
+	if err := verifySignature(header.Alg, key, parts[0]+"."+parts[1], sig); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: malformed JWT claims", ErrInvalidToken)
+	}
+	return m.validateClaims(claims)
+}
+
+// key picks the verification key by kid. Tokens without a kid are only
+// accepted when the JWKS holds a single key.
+func (m *jwtMethod) key(kid string) (crypto.PublicKey, error) {
+	if kid == "" && len(m.keys) == 1 {
+		for _, key := range m.keys {
+			return key, nil
+		}
+	}
+	key, ok := m.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown JWT key id %q", ErrInvalidToken, kid)
+	}
+	return key, nil
+}
+
+func (m *jwtMethod) validateClaims(claims map[string]interface{}) (*Principal, error) {
+	now := m.now()
+	exp, ok := numericClaim(claims, "exp")
+	if !ok {
+		return nil, fmt.Errorf("%w: JWT has no exp claim", ErrInvalidToken)
+	}
+	if now.After(time.Unix(exp, 0).Add(m.leeway)) {
+		return nil, fmt.Errorf("%w: JWT expired", ErrInvalidToken)
+	}
+	if nbf, ok := numericClaim(claims, "nbf"); ok && now.Add(m.leeway).Before(time.Unix(nbf, 0)) {
+		return nil, fmt.Errorf("%w: JWT not yet valid", ErrInvalidToken)
+	}
+	if iss, _ := claims["iss"].(string); iss != m.issuer {
+		return nil, fmt.Errorf("%w: unexpected JWT issuer %q", ErrInvalidToken, iss)
+	}
+	if !containsString(stringsClaim(claims["aud"]), m.audience) {
+		return nil, fmt.Errorf("%w: JWT audience doesn't include %q", ErrInvalidToken, m.audience)
+	}
+	sub, _ := claims["sub"].(string)
+	if sub == "" {
+		return nil, fmt.Errorf("%w: JWT has no sub claim", ErrInvalidToken)
+	}
+
+	// scope is a space separated string (RFC 8693), scp a list on some servers
+	var scopes []string
+	if scope, ok := claims["scope"].(string); ok {
+		scopes = strings.Fields(scope)
+	} else {
+		scopes = stringsClaim(claims["scp"])
+	}
+	for _, required := range m.requiredScopes {
+		if !containsString(scopes, required) {
+			return nil, fmt.Errorf("%w: missing scope %q", ErrInsufficientScope, required)
+		}
+	}
+
+	tenant, _ := claims[m.tenantClaim].(string)
+	return &Principal{Subject: sub, Tenant: tenant, Scopes: scopes}, nil
+}
+
+// verifySignature checks sig over signed with one of the asymmetric JWS
+// algorithms. "none" and the HS* family are rejected outright.
+func verifySignature(alg string, key crypto.PublicKey, signed string, sig []byte) error {
+	var hash crypto.Hash
+	switch alg {
+	case "RS256", "PS256", "ES256":
+		hash = crypto.SHA256
+	case "RS384", "PS384", "ES384":
+		hash = crypto.SHA384
+	case "RS512", "PS512", "ES512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported JWT algorithm %q", alg)
+	}
+	h := hash.New()
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+
+	switch alg[:2] {
+	case "RS", "PS":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key type doesn't match algorithm %s", alg)
+		}
+		if alg[0] == 'P' {
+			// RFC 7518 3.5 fixes the salt length to the hash size
+			return rsa.VerifyPSS(pub, hash, digest, sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		}
+		return rsa.VerifyPKCS1v15(pub, hash, digest, sig)
+	default:
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key type doesn't match algorithm %s", alg)
+		}
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return fmt.Errorf("bad ECDSA signature length")
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return fmt.Errorf("bad ECDSA signature")
+		}
+		return nil
+	}
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// loadJWKS reads the RSA and EC signing keys from a JWKS file
+func loadJWKS(path string) (map[string]crypto.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS file %s: %w", path, err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for i, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("JWKS file %s: key %d: %w", path, i, err)
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("JWKS file %s contains no signing keys", path)
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil || len(e) > 4 {
+			return nil, fmt.Errorf("invalid exponent")
+		}
+		pub := &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+		if pub.N.BitLen() < 2048 {
+			return nil, fmt.Errorf("RSA key shorter than 2048 bits")
+		}
+		return pub, nil
+	case "EC":
+		var curve elliptic.Curve
+		var ecdhCurve ecdh.Curve
+		switch k.Crv {
+		case "P-256":
+			curve, ecdhCurve = elliptic.P256(), ecdh.P256()
+		case "P-384":
+			curve, ecdhCurve = elliptic.P384(), ecdh.P384()
+		case "P-521":
+			curve, ecdhCurve = elliptic.P521(), ecdh.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, errX := base64.RawURLEncoding.DecodeString(k.X)
+		y, errY := base64.RawURLEncoding.DecodeString(k.Y)
+		size := (curve.Params().BitSize + 7) / 8
+		if errX != nil || errY != nil || len(x) != size || len(y) != size {
+			return nil, fmt.Errorf("invalid EC coordinates")
+		}
+		// Reject points that aren't on the curve
+		point := append(append([]byte{4}, x...), y...)
+		if _, err := ecdhCurve.NewPublicKey(point); err != nil {
+			return nil, fmt.Errorf("invalid EC point: %w", err)
+		}
+		return &ecdsa.PublicKey{
+			Curve: curve,
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeSegment(segment string, out interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+func numericClaim(claims map[string]interface{}, name string) (int64, bool) {
+	v, ok := claims[name].(float64)
+	return int64(v), ok
+}
+
+// stringsClaim reads a claim that may be a single string or a list of strings
+func stringsClaim(v interface{}) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
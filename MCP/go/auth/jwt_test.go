@@ -0,0 +1,313 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/neutrino-api/mcp-server/config"
+)
+
+var (
+	testRSAKey, _ = rsa.GenerateKey(rand.Reader, 2048)
+	testECKey, _  = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	testNow       = time.Unix(1_800_000_000, 0)
+)
+
+const (
+	testIssuer   = "https://auth.example.com"
+	testAudience = "https://mcp.example.com"
+)
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func b64JSON(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return b64(data)
+}
+
+// signJWT creates a token signed with key, an RSA or EC private key or an
+// HMAC secret
+func signJWT(t *testing.T, alg, kid string, key any, claims map[string]any) string {
+	t.Helper()
+	header := map[string]string{"alg": alg, "typ": "JWT"}
+	if kid != "" {
+		header["kid"] = kid
+	}
+	signed := b64JSON(header) + "." + b64JSON(claims)
+	if alg == "none" {
+		return signed + "."
+	}
+
+	hash := map[string]crypto.Hash{"256": crypto.SHA256, "384": crypto.SHA384, "512": crypto.SHA512}[alg[2:]]
+	h := hash.New()
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+	var sig []byte
+	var err error
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		if alg[0] == 'P' {
+			sig, err = rsa.SignPSS(rand.Reader, key, hash, digest, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		} else {
+			sig, err = rsa.SignPKCS1v15(rand.Reader, key, hash, digest)
+		}
+	case *ecdsa.PrivateKey:
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, key, digest)
+		size := (key.Curve.Params().BitSize + 7) / 8
+		sig = make([]byte, 2*size)
+		r.FillBytes(sig[:size])
+		s.FillBytes(sig[size:])
+	case []byte:
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(signed))
+		sig = mac.Sum(nil)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + b64(sig)
+}
+
+func validClaims() map[string]any {
+	return map[string]any{
+		"iss":    testIssuer,
+		"aud":    testAudience,
+		"sub":    "client-1",
+		"exp":    testNow.Add(time.Hour).Unix(),
+		"nbf":    testNow.Add(-time.Minute).Unix(),
+		"scope":  "mcp:tools other",
+		"tenant": "acme",
+	}
+}
+
+// with sets a claim, or removes it when value is nil
+func with(claims map[string]any, name string, value any) map[string]any {
+	if value == nil {
+		delete(claims, name)
+	} else {
+		claims[name] = value
+	}
+	return claims
+}
+
+func rsaJWK(kid string, pub *rsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA", "kid": kid, "use": "sig",
+		"n": b64(pub.N.Bytes()),
+		"e": b64(big.NewInt(int64(pub.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, pub *ecdsa.PublicKey) map[string]string {
+	size := (pub.Curve.Params().BitSize + 7) / 8
+	return map[string]string{
+		"kty": "EC", "kid": kid, "crv": pub.Curve.Params().Name,
+		"x": b64(pub.X.FillBytes(make([]byte, size))),
+		"y": b64(pub.Y.FillBytes(make([]byte, size))),
+	}
+}
+
+func writeJWKS(t *testing.T, keys ...map[string]string) string {
+	t.Helper()
+	data, err := json.Marshal(map[string]any{"keys": keys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func testJWTConfig(t *testing.T) *config.AuthConfig {
+	return &config.AuthConfig{
+		Methods:        []string{"jwt"},
+		JWKSFile:       writeJWKS(t, rsaJWK("rsa", &testRSAKey.PublicKey), ecJWK("ec", &testECKey.PublicKey)),
+		JWTIssuer:      testIssuer,
+		JWTAudience:    testAudience,
+		JWTTenantClaim: "tenant",
+		JWTLeeway:      time.Minute,
+		RequiredScopes: []string{"mcp:tools"},
+		ResourceURL:    testAudience,
+	}
+}
+
+func newTestJWTMethod(t *testing.T) *jwtMethod {
+	t.Helper()
+	m, err := newJWTMethod(testJWTConfig(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.now = func() time.Time { return testNow }
+	return m
+}
+
+func TestJWTAccepts(t *testing.T) {
+	m := newTestJWTMethod(t)
+	for _, tc := range []struct {
+		name  string
+		token string
+	}{
+		{"RS256", signJWT(t, "RS256", "rsa", testRSAKey, validClaims())},
+		{"RS512", signJWT(t, "RS512", "rsa", testRSAKey, validClaims())},
+		{"PS256", signJWT(t, "PS256", "rsa", testRSAKey, validClaims())},
+		{"PS384", signJWT(t, "PS384", "rsa", testRSAKey, validClaims())},
+		{"ES256", signJWT(t, "ES256", "ec", testECKey, validClaims())},
+		{"aud list", signJWT(t, "RS256", "rsa", testRSAKey, with(validClaims(), "aud", []string{"other", testAudience}))},
+		{"scp list", signJWT(t, "RS256", "rsa", testRSAKey, with(with(validClaims(), "scope", nil), "scp", []string{"mcp:tools"}))},
+		{"expired within leeway", signJWT(t, "RS256", "rsa", testRSAKey, with(validClaims(), "exp", testNow.Add(-30*time.Second).Unix()))},
+		{"nbf within leeway", signJWT(t, "RS256", "rsa", testRSAKey, with(validClaims(), "nbf", testNow.Add(30*time.Second).Unix()))},
+		{"no nbf", signJWT(t, "RS256", "rsa", testRSAKey, with(validClaims(), "nbf", nil))},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := m.Authenticate(tc.token)
+			if err != nil {
+				t.Fatalf("Authenticate: %v", err)
+			}
+			if p.Subject != "client-1" || p.Tenant != "acme" || !containsString(p.Scopes, "mcp:tools") {
+				t.Errorf("principal = %+v", p)
+			}
+		})
+	}
+}
+
+func TestJWTRejects(t *testing.T) {
+	m := newTestJWTMethod(t)
+	valid := signJWT(t, "RS256", "rsa", testRSAKey, validClaims())
+	parts := strings.Split(valid, ".")
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	maxSalt := func() string {
+		// PSS with the salt as long as the key allows, which RFC 7518 forbids
+		signed := b64JSON(map[string]string{"alg": "PS256", "kid": "rsa"}) + "." + b64JSON(validClaims())
+		digest := sha256.Sum256([]byte(signed))
+		sig, err := rsa.SignPSS(rand.Reader, testRSAKey, crypto.SHA256, digest[:], &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed + "." + b64(sig)
+	}()
+
+	for _, tc := range []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{"not a JWT", "abc.def", ErrInvalidToken},
+		{"alg none", signJWT(t, "none", "rsa", nil, validClaims()), ErrInvalidToken},
+		// HS256 keyed with the public key, the classic algorithm confusion
+		{"HS256", signJWT(t, "HS256", "rsa", testRSAKey.PublicKey.N.Bytes(), validClaims()), ErrInvalidToken},
+		{"unknown kid", signJWT(t, "RS256", "other", testRSAKey, validClaims()), ErrInvalidToken},
+		{"no kid with several keys", signJWT(t, "RS256", "", testRSAKey, validClaims()), ErrInvalidToken},
+		{"other signer", signJWT(t, "RS256", "rsa", otherKey, validClaims()), ErrInvalidToken},
+		{"alg doesn't match key", signJWT(t, "ES256", "rsa", testECKey, validClaims()), ErrInvalidToken},
+		{"tampered payload", parts[0] + "." + b64JSON(with(validClaims(), "sub", "admin")) + "." + parts[2], ErrInvalidToken},
+		{"PS256 with maximum salt", maxSalt, ErrInvalidToken},
+		{"expired", signJWT(t, "RS256", "rsa", testRSAKey, with(validClaims(), "exp", testNow.Add(-2*time.Minute).Unix())), ErrInvalidToken},
+		{"no exp", signJWT(t, "RS256", "rsa", testRSAKey, with(validClaims(), "exp", nil)), ErrInvalidToken},
+		{"not yet valid", signJWT(t, "RS256", "rsa", testRSAKey, with(validClaims(), "nbf", testNow.Add(2*time.Minute).Unix())), ErrInvalidToken},
+		{"wrong issuer", signJWT(t, "RS256", "rsa", testRSAKey, with(validClaims(), "iss", "https://evil.example.com")), ErrInvalidToken},
+		{"wrong audience", signJWT(t, "RS256", "rsa", testRSAKey, with(validClaims(), "aud", "https://other.example.com")), ErrInvalidToken},
+		{"audience list without ours", signJWT(t, "RS256", "rsa", testRSAKey, with(validClaims(), "aud", []string{"a", "b"})), ErrInvalidToken},
+		{"no sub", signJWT(t, "RS256", "rsa", testRSAKey, with(validClaims(), "sub", nil)), ErrInvalidToken},
+		{"missing scope", signJWT(t, "RS256", "rsa", testRSAKey, with(validClaims(), "scope", "other")), ErrInsufficientScope},
+		{"scp without scope", signJWT(t, "RS256", "rsa", testRSAKey, with(with(validClaims(), "scope", nil), "scp", []string{"other"})), ErrInsufficientScope},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := m.Authenticate(tc.token)
+			if !errors.Is(err, tc.err) {
+				t.Errorf("err = %v, want %v", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestJWTTruncatedECDSASignature(t *testing.T) {
+	m := newTestJWTMethod(t)
+	token := signJWT(t, "ES256", "ec", testECKey, validClaims())
+	i := strings.LastIndexByte(token, '.')
+	sig, _ := base64.RawURLEncoding.DecodeString(token[i+1:])
+	// An ASN.1 style or short signature must not be read as r || s
+	if _, err := m.Authenticate(token[:i+1] + b64(sig[:len(sig)-1])); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("err = %v, want %v", err, ErrInvalidToken)
+	}
+}
+
+func TestJWTSingleKeyWithoutKid(t *testing.T) {
+	cfg := testJWTConfig(t)
+	cfg.JWKSFile = writeJWKS(t, ecJWK("only", &testECKey.PublicKey))
+	m, err := newJWTMethod(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.now = func() time.Time { return testNow }
+	if _, err := m.Authenticate(signJWT(t, "ES256", "", testECKey, validClaims())); err != nil {
+		t.Errorf("Authenticate: %v", err)
+	}
+}
+
+func TestLoadJWKS(t *testing.T) {
+	smallKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	offCurve := ecJWK("ec", &testECKey.PublicKey)
+	offCurve["y"] = offCurve["x"]
+	encryption := rsaJWK("enc", &testRSAKey.PublicKey)
+	encryption["use"] = "enc"
+
+	keys, err := loadJWKS(writeJWKS(t, rsaJWK("rsa", &testRSAKey.PublicKey), encryption))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 {
+		t.Errorf("loaded %d keys, want the signing key only", len(keys))
+	}
+	if pub, ok := keys["rsa"].(*rsa.PublicKey); !ok || pub.E != 65537 || pub.N.Cmp(testRSAKey.N) != 0 {
+		t.Errorf("RSA key = %+v", keys["rsa"])
+	}
+
+	for name, key := range map[string]map[string]string{
+		"short RSA key":   rsaJWK("small", &smallKey.PublicKey),
+		"point off curve": offCurve,
+		"long exponent":   withField(rsaJWK("rsa", &testRSAKey.PublicKey), "e", b64([]byte{1, 0, 0, 0, 1})),
+		"unknown curve":   withField(ecJWK("ec", &testECKey.PublicKey), "crv", "P-192"),
+		"symmetric key":   {"kty": "oct", "k": b64([]byte("secret"))},
+	} {
+		if _, err := loadJWKS(writeJWKS(t, key)); err == nil {
+			t.Errorf("%s: loaded, want an error", name)
+		}
+	}
+	if _, err := loadJWKS(writeJWKS(t, encryption)); err == nil {
+		t.Error("JWKS without signing keys loaded, want an error")
+	}
+}
+
+// withField sets a field of a JWK
+func withField(key map[string]string, name, value string) map[string]string {
+	key[name] = value
+	return key
+}
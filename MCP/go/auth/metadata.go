@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// metadataPath is the well-known path of the OAuth 2.0 protected resource
+// metadata (RFC 9728) that MCP clients use to discover the authorization server
+const metadataPath = "/.well-known/oauth-protected-resource"
+
+// ResourceMetadata is the protected resource metadata document
+type ResourceMetadata struct {
+	Resource               string   `json:"resource"`
+	AuthorizationServers   []string `json:"authorization_servers,omitempty"`
+	ScopesSupported        []string `json:"scopes_supported,omitempty"`
+	BearerMethodsSupported []string `json:"bearer_methods_supported,omitempty"`
+}
+
+// RegisterMetadata serves the protected resource metadata on mux, both at
+// the well-known root and suffixed with the resource path (e.g. /mcp) as
+// RFC 9728 describes for resources with a path component
+func (a *Authenticator) RegisterMetadata(mux *http.ServeMux) {
+	if a.resource == nil {
+		return
+	}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(a.resource)
+	})
+	mux.Handle(metadataPath, handler)
+	if u, err := url.Parse(a.resource.Resource); err == nil && u.Path != "" && u.Path != "/" {
+		mux.Handle(metadataPath+u.Path, handler)
+	}
+}
+
+// metadataURL is the absolute URL of the metadata document advertised in
+// WWW-Authenticate challenges
+func (m *ResourceMetadata) metadataURL() string {
+	u, err := url.Parse(m.Resource)
+	if err != nil {
+		return m.Resource + metadataPath
+	}
+	u.Path = metadataPath + strings.TrimRight(u.Path, "/")
+	u.RawQuery, u.Fragment = "", ""
+	return u.String()
+}
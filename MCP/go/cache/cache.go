@@ -0,0 +1,39 @@
+// Package cache stores upstream API responses so repeated lookups don't
+// cost API credits
+package cache
+
+import "time"
+
+// Store is a key-value store with per-entry expiry
+type Store interface {
+	// Get returns the value stored under key and when it expires, unless it
+	// is missing or expired
+	Get(key string) ([]byte, time.Time, bool)
+	// Set stores value under key for ttl
+	Set(key string, value []byte, ttl time.Duration)
+}
+
+// Tiered checks several stores in order, typically memory in front of disk.
+// Hits in a later store are copied to the earlier ones for the rest of the
+// entry's lifetime; Set writes to all of them.
+type Tiered []Store
+
+func (t Tiered) Get(key string) ([]byte, time.Time, bool) {
+	for i, store := range t {
+		value, expires, ok := store.Get(key)
+		if !ok {
+			continue
+		}
+		for _, earlier := range t[:i] {
+			earlier.Set(key, value, time.Until(expires))
+		}
+		return value, expires, true
+	}
+	return nil, time.Time{}, false
+}
+
+func (t Tiered) Set(key string, value []byte, ttl time.Duration) {
+	for _, store := range t {
+		store.Set(key, value, ttl)
+	}
+}
@@ -0,0 +1,333 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// diskFile is the name of the cache database inside the cache directory
+const diskFile = "responses.db"
+
+// errLockTimeout is returned when the lock file stays locked for longer
+// than the lock timeout
+var errLockTimeout = errors.New("timed out waiting for the cache lock")
+
+var (
+	entriesBucket = []byte("entries") // key -> expires, stored, value
+	orderBucket   = []byte("order")   // stored, key -> nothing, oldest first
+	metaBucket    = []byte("meta")
+	sizeKey       = []byte("size") // Total bytes of all keys and values
+)
+
+// Disk is a Store kept in a single bbolt file, so cached responses survive
+// restarts. The file is opened for every operation rather than held open:
+// bbolt locks it for the duration, letting several server processes on one
+// machine share the cache by taking turns.
+//
+// Every operation also holds a shared lock on a lock file beside the cache
+// file from before it opens the file until it closes it. Compact takes that
+// lock exclusively, so nobody has the old file open when it is replaced and
+// those waiting open the new one.
+//
+// When the entries exceed the size limit the oldest are evicted. Deleted
+// space is only returned to the OS by Compact.
+type Disk struct {
+	path        string
+	maxBytes    int64
+	lockTimeout time.Duration
+	now         func() time.Time
+}
+
+// OpenDisk opens or creates the cache file in dir, keeping at most maxBytes
+// of keys and values
+func OpenDisk(dir string, maxBytes int64) (*Disk, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	d := &Disk{
+		path:        filepath.Join(dir, diskFile),
+		maxBytes:    maxBytes,
+		lockTimeout: 5 * time.Second,
+		now:         time.Now,
+	}
+	err := d.update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{entriesBucket, orderBucket, metaBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cache file %s: %w", d.path, err)
+	}
+	return d, nil
+}
+
+// Path returns the cache file
+func (d *Disk) Path() string {
+	return d.path
+}
+
+func (d *Disk) Get(key string) ([]byte, time.Time, bool) {
+	var value []byte
+	var expires time.Time
+	err := d.view(func(tx *bolt.Tx) error {
+		data := tx.Bucket(entriesBucket).Get([]byte(key))
+		if data == nil {
+			return nil
+		}
+		e, _, v, err := decodeEntry(data)
+		if err != nil {
+			return err
+		}
+		if d.now().Before(e) {
+			// bbolt's memory is only valid within the transaction
+			value, expires = bytes.Clone(v), e
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Cache read failed: %v", err)
+		return nil, time.Time{}, false
+	}
+	return value, expires, value != nil
+}
+
+func (d *Disk) Set(key string, value []byte, ttl time.Duration) {
+	err := d.update(func(tx *bolt.Tx) error {
+		entries, order := tx.Bucket(entriesBucket), tx.Bucket(orderBucket)
+		size := readSize(tx)
+		if err := deleteEntry(entries, order, []byte(key), &size); err != nil {
+			return err
+		}
+
+		now := d.now()
+		k := []byte(key)
+		if err := entries.Put(k, encodeEntry(now.Add(ttl), now, value)); err != nil {
+			return err
+		}
+		if err := order.Put(orderKey(now, k), nil); err != nil {
+			return err
+		}
+		size += int64(len(k) + len(value))
+
+		// Evict the oldest entries until the new one fits
+		c := order.Cursor()
+		for oldest, _ := c.First(); size > d.maxBytes && oldest != nil; oldest, _ = c.First() {
+			key := bytes.Clone(oldest[8:])
+			if err := c.Delete(); err != nil {
+				return err
+			}
+			if err := deleteEntry(entries, order, key, &size); err != nil {
+				return err
+			}
+		}
+		return writeSize(tx, size)
+	})
+	if err != nil {
+		log.Printf("Cache write failed: %v", err)
+	}
+}
+
+// Purge deletes all expired entries
+func (d *Disk) Purge() error {
+	return d.update(func(tx *bolt.Tx) error {
+		return d.purge(tx)
+	})
+}
+
+func (d *Disk) purge(tx *bolt.Tx) error {
+	entries, order := tx.Bucket(entriesBucket), tx.Bucket(orderBucket)
+	size := readSize(tx)
+	now := d.now()
+	var expired [][]byte
+	err := entries.ForEach(func(k, v []byte) error {
+		if expires, _, _, err := decodeEntry(v); err != nil || !now.Before(expires) {
+			expired = append(expired, bytes.Clone(k))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, k := range expired {
+		if err := deleteEntry(entries, order, k, &size); err != nil {
+			return err
+		}
+	}
+	return writeSize(tx, size)
+}
+
+// Compact purges expired entries and, when most of the file is free space
+// left behind by deleted entries, rewrites it to release that space. Other
+// processes wait for it to finish. Where the lock file can't be locked the
+// file is only purged.
+//
+// Compact fails with errLockTimeout if the cache stays busy for the whole
+// lock timeout, it is then up to the caller to try again later.
+func (d *Disk) Compact() error {
+	unlock, err := d.lock(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	db, err := d.open(false)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var live int64
+	err = db.Update(func(tx *bolt.Tx) error {
+		if err := d.purge(tx); err != nil {
+			return err
+		}
+		live = readSize(tx)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(d.path)
+	if err != nil {
+		return err
+	}
+	// Small files and files that are mostly live data aren't worth rewriting
+	if !lockSupported || info.Size() < 1<<20 || info.Size() < 2*live {
+		return nil
+	}
+
+	tmp := d.path + ".compact"
+	os.Remove(tmp)
+	dst, err := bolt.Open(tmp, 0o600, &bolt.Options{Timeout: d.lockTimeout})
+	if err != nil {
+		return err
+	}
+	if err := bolt.Compact(dst, db, 64<<20); err != nil {
+		dst.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	// Nobody else has the file open while the lock file is held exclusively
+	if err := os.Rename(tmp, d.path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	log.Printf("Compacted cache file %s from %d bytes", d.path, info.Size())
+	return nil
+}
+
+// lock locks the lock file, shared for reads and writes or exclusive for
+// replacing the cache file. The returned func unlocks it.
+func (d *Disk) lock(exclusive bool) (func(), error) {
+	f, err := os.OpenFile(d.path+".lock", os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f, exclusive, d.lockTimeout); err != nil {
+		f.Close()
+		return nil, err
+	}
+	// Closing the file releases the lock
+	return func() { f.Close() }, nil
+}
+
+func (d *Disk) open(readOnly bool) (*bolt.DB, error) {
+	return bolt.Open(d.path, 0o600, &bolt.Options{Timeout: d.lockTimeout, ReadOnly: readOnly})
+}
+
+func (d *Disk) view(fn func(*bolt.Tx) error) error {
+	unlock, err := d.lock(false)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	db, err := d.open(true)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.View(fn)
+}
+
+func (d *Disk) update(fn func(*bolt.Tx) error) error {
+	unlock, err := d.lock(false)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	db, err := d.open(false)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.Update(fn)
+}
+
+// deleteEntry removes key from both buckets and deducts it from size
+func deleteEntry(entries, order *bolt.Bucket, key []byte, size *int64) error {
+	data := entries.Get(key)
+	if data == nil {
+		return nil
+	}
+	_, stored, value, err := decodeEntry(data)
+	if err == nil {
+		*size -= int64(len(key) + len(value))
+		if err := order.Delete(orderKey(stored, key)); err != nil {
+			return err
+		}
+	}
+	return entries.Delete(key)
+}
+
+func encodeEntry(expires, stored time.Time, value []byte) []byte {
+	data := make([]byte, 16+len(value))
+	binary.BigEndian.PutUint64(data, uint64(expires.UnixNano()))
+	binary.BigEndian.PutUint64(data[8:], uint64(stored.UnixNano()))
+	copy(data[16:], value)
+	return data
+}
+
+func decodeEntry(data []byte) (expires, stored time.Time, value []byte, err error) {
+	if len(data) < 16 {
+		return time.Time{}, time.Time{}, nil, errors.New("corrupt cache entry")
+	}
+	expires = time.Unix(0, int64(binary.BigEndian.Uint64(data)))
+	stored = time.Unix(0, int64(binary.BigEndian.Uint64(data[8:])))
+	return expires, stored, data[16:], nil
+}
+
+// orderKey sorts entries by the time they were stored
+func orderKey(stored time.Time, key []byte) []byte {
+	k := make([]byte, 8+len(key))
+	binary.BigEndian.PutUint64(k, uint64(stored.UnixNano()))
+	copy(k[8:], key)
+	return k
+}
+
+func readSize(tx *bolt.Tx) int64 {
+	v := tx.Bucket(metaBucket).Get(sizeKey)
+	if len(v) != 8 {
+		return 0
+	}
+	return int64(binary.BigEndian.Uint64(v))
+}
+
+func writeSize(tx *bolt.Tx, size int64) error {
+	v := make([]byte, 8)
+	binary.BigEndian.PutUint64(v, uint64(max(size, 0)))
+	return tx.Bucket(metaBucket).Put(sizeKey, v)
+}
@@ -0,0 +1,327 @@
+package cache
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var testNow = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// openTestDisk opens a cache in dir whose clock is *clock
+func openTestDisk(t *testing.T, dir string, maxBytes int64, clock *time.Time) *Disk {
+	t.Helper()
+	d, err := OpenDisk(dir, maxBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.now = func() time.Time { return *clock }
+	return d
+}
+
+// checkSize compares the recorded size with the entries actually stored and
+// returns it
+func checkSize(t *testing.T, d *Disk) int64 {
+	t.Helper()
+	var recorded, counted int64
+	var entries, ordered int
+	err := d.view(func(tx *bolt.Tx) error {
+		recorded = readSize(tx)
+		ordered = tx.Bucket(orderBucket).Stats().KeyN
+		return tx.Bucket(entriesBucket).ForEach(func(k, v []byte) error {
+			entries++
+			counted += int64(len(k) + len(v) - 16)
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recorded != counted {
+		t.Errorf("recorded size = %d, entries hold %d bytes", recorded, counted)
+	}
+	if entries != ordered {
+		t.Errorf("%d entries but %d in the eviction order", entries, ordered)
+	}
+	return recorded
+}
+
+func keys(t *testing.T, d *Disk) []string {
+	t.Helper()
+	var out []string
+	err := d.view(func(tx *bolt.Tx) error {
+		return tx.Bucket(entriesBucket).ForEach(func(k, _ []byte) error {
+			out = append(out, string(k))
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestDiskGetSet(t *testing.T) {
+	clock := testNow
+	d := openTestDisk(t, t.TempDir(), 1<<20, &clock)
+
+	if _, _, ok := d.Get("a"); ok {
+		t.Error("hit in an empty cache")
+	}
+	d.Set("a", []byte("first"), time.Minute)
+	value, expires, ok := d.Get("a")
+	if !ok || string(value) != "first" || !expires.Equal(testNow.Add(time.Minute)) {
+		t.Errorf("Get(a) = %q, %v, %v", value, expires, ok)
+	}
+
+	// Replacing an entry replaces its size too
+	d.Set("a", []byte("second value"), time.Minute)
+	if value, _, _ := d.Get("a"); string(value) != "second value" {
+		t.Errorf("Get(a) = %q after replacing it", value)
+	}
+	if size := checkSize(t, d); size != int64(len("a")+len("second value")) {
+		t.Errorf("size = %d after replacing an entry", size)
+	}
+}
+
+func TestDiskExpiry(t *testing.T) {
+	clock := testNow
+	d := openTestDisk(t, t.TempDir(), 1<<20, &clock)
+	d.Set("short", []byte("1"), time.Minute)
+	d.Set("long", []byte("2"), time.Hour)
+
+	clock = testNow.Add(time.Minute)
+	if _, _, ok := d.Get("short"); ok {
+		t.Error("hit on an entry at its expiry")
+	}
+	if _, _, ok := d.Get("long"); !ok {
+		t.Error("miss on an entry before its expiry")
+	}
+
+	// Expired entries stay until purged
+	if got := keys(t, d); len(got) != 2 {
+		t.Errorf("keys before purge = %v", got)
+	}
+	if err := d.Purge(); err != nil {
+		t.Fatal(err)
+	}
+	if got := keys(t, d); len(got) != 1 || got[0] != "long" {
+		t.Errorf("keys after purge = %v, want [long]", got)
+	}
+	if size := checkSize(t, d); size != int64(len("long")+1) {
+		t.Errorf("size = %d after purge", size)
+	}
+}
+
+func TestDiskEviction(t *testing.T) {
+	clock := testNow
+	// Room for three 10 byte entries
+	d := openTestDisk(t, t.TempDir(), 30, &clock)
+	for _, k := range []string{"k1", "k2", "k3"} {
+		d.Set(k, bytes.Repeat([]byte("x"), 8), time.Hour)
+		clock = clock.Add(time.Second)
+	}
+	if got := keys(t, d); len(got) != 3 {
+		t.Fatalf("keys = %v, want all three", got)
+	}
+
+	// Storing k1 again makes it the newest, so k2 goes when k4 arrives
+	d.Set("k1", bytes.Repeat([]byte("x"), 8), time.Hour)
+	clock = clock.Add(time.Second)
+	d.Set("k4", bytes.Repeat([]byte("x"), 8), time.Hour)
+	if got := fmt.Sprint(keys(t, d)); got != "[k1 k3 k4]" {
+		t.Errorf("keys = %s, want [k1 k3 k4]", got)
+	}
+	if size := checkSize(t, d); size != 30 {
+		t.Errorf("size = %d, want 30", size)
+	}
+
+	// An entry larger than the limit evicts everything, itself included
+	clock = clock.Add(time.Second)
+	d.Set("big", bytes.Repeat([]byte("x"), 40), time.Hour)
+	if got := keys(t, d); len(got) != 0 {
+		t.Errorf("keys = %v after an oversized entry", got)
+	}
+	if size := checkSize(t, d); size != 0 {
+		t.Errorf("size = %d, want 0", size)
+	}
+}
+
+// TestDiskReusesSpace checks that the file stops growing at about the size
+// limit between compactions
+func TestDiskReusesSpace(t *testing.T) {
+	clock := testNow
+	d := openTestDisk(t, t.TempDir(), 64<<10, &clock)
+	value := bytes.Repeat([]byte("x"), 1000)
+	fileSize := func() int64 {
+		info, err := os.Stat(d.Path())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return info.Size()
+	}
+
+	for i := 0; i < 500; i++ {
+		d.Set(fmt.Sprintf("key-%d", i), value, time.Hour)
+		clock = clock.Add(time.Millisecond)
+	}
+	filled := fileSize()
+	for i := 500; i < 5000; i++ {
+		d.Set(fmt.Sprintf("key-%d", i), value, time.Hour)
+		clock = clock.Add(time.Millisecond)
+	}
+	if size := fileSize(); size > 2*filled {
+		t.Errorf("file grew from %d to %d bytes at a constant size limit", filled, size)
+	}
+	checkSize(t, d)
+}
+
+func fileSize(t *testing.T, path string) int64 {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info.Size()
+}
+
+// fillExpiring stores about 4 MiB of entries expiring after a minute
+func fillExpiring(d *Disk) {
+	value := bytes.Repeat([]byte("x"), 4000)
+	for i := 0; i < 1000; i++ {
+		d.Set(fmt.Sprintf("expiring-%d", i), value, time.Minute)
+	}
+}
+
+func TestDiskCompact(t *testing.T) {
+	clock := testNow
+	d := openTestDisk(t, t.TempDir(), 64<<20, &clock)
+	fillExpiring(d)
+	d.Set("kept", []byte("value"), time.Hour)
+
+	before := fileSize(t, d.Path())
+	clock = testNow.Add(2 * time.Minute)
+	if err := d.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	if size := fileSize(t, d.Path()); size >= 1<<20 {
+		t.Errorf("file is %d bytes after compacting, was %d", size, before)
+	}
+	if value, _, ok := d.Get("kept"); !ok || string(value) != "value" {
+		t.Errorf("Get(kept) = %q, %v after compacting", value, ok)
+	}
+	if got := keys(t, d); len(got) != 1 {
+		t.Errorf("%d keys after compacting, want 1", len(got))
+	}
+	checkSize(t, d)
+}
+
+// TestDiskCompactWhileShared compacts the file repeatedly while another
+// Disk on the same directory keeps writing to it
+func TestDiskCompactWhileShared(t *testing.T) {
+	if !lockSupported {
+		t.Skip("the cache file is never replaced on this platform")
+	}
+	dir := t.TempDir()
+	clockA, clockB := testNow, testNow
+	a := openTestDisk(t, dir, 64<<20, &clockA)
+	b := openTestDisk(t, dir, 64<<20, &clockB)
+
+	stop, done := make(chan struct{}), make(chan struct{})
+	var written []string
+	go func() {
+		defer close(done)
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			key := fmt.Sprintf("b-%d", i)
+			b.Set(key, []byte(key), time.Hour)
+			written = append(written, key)
+			// Busy, but leaving gaps for the exclusive lock as real
+			// traffic does
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	for i := 0; i < 5; i++ {
+		fillExpiring(a)
+		before := fileSize(t, a.Path())
+		clockA = clockA.Add(2 * time.Minute)
+		if err := a.Compact(); err != nil {
+			t.Error(err)
+			break
+		}
+		if size := fileSize(t, a.Path()); size >= before/2 {
+			t.Errorf("file is %d bytes after compacting, was %d", size, before)
+		}
+	}
+	close(stop)
+	<-done
+
+	// None of b's writes went to a replaced file
+	c := openTestDisk(t, dir, 64<<20, &clockB)
+	lost := 0
+	for _, key := range written {
+		if _, _, ok := c.Get(key); !ok {
+			lost++
+		}
+	}
+	if lost > 0 {
+		t.Errorf("%d of %d writes lost", lost, len(written))
+	}
+	checkSize(t, c)
+}
+
+// TestDiskShared runs two processes' worth of caches on one directory
+func TestDiskShared(t *testing.T) {
+	dir := t.TempDir()
+	clock := testNow
+	a := openTestDisk(t, dir, 1<<20, &clock)
+	b := openTestDisk(t, dir, 1<<20, &clock)
+
+	a.Set("from-a", []byte("1"), time.Hour)
+	if value, _, ok := b.Get("from-a"); !ok || string(value) != "1" {
+		t.Errorf("b.Get(from-a) = %q, %v", value, ok)
+	}
+
+	var wg sync.WaitGroup
+	for i, d := range []*Disk{a, b, a, b} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				key := fmt.Sprintf("writer-%d-%d", i, j)
+				d.Set(key, []byte(key), time.Hour)
+				if value, _, ok := d.Get(key); !ok || string(value) != key {
+					t.Errorf("Get(%s) = %q, %v right after Set", key, value, ok)
+				}
+				if j%10 == 0 {
+					if err := d.Purge(); err != nil {
+						t.Error(err)
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// No write was lost and both see every entry
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 50; j++ {
+			key := fmt.Sprintf("writer-%d-%d", i, j)
+			for name, d := range map[string]*Disk{"a": a, "b": b} {
+				if _, _, ok := d.Get(key); !ok {
+					t.Errorf("%s misses %s", name, key)
+				}
+			}
+		}
+	}
+	checkSize(t, a)
+}
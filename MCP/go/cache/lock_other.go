@@ -0,0 +1,16 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package cache
+
+import (
+	"os"
+	"time"
+)
+
+// lockSupported is false where flock isn't available. The cache file is
+// then still shared through bbolt's own lock but never replaced.
+const lockSupported = false
+
+func lockFile(f *os.File, exclusive bool, timeout time.Duration) error {
+	return nil
+}
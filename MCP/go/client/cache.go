@@ -0,0 +1,182 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"expvar"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/neutrino-api/mcp-server/cache"
+	"github.com/neutrino-api/mcp-server/config"
+	"github.com/neutrino-api/mcp-server/models"
+)
+
+// BypassCacheArgument is the tool argument that forces a fresh lookup. The
+// response still refreshes the cache.
+const BypassCacheArgument = "no-cache"
+
+// Cache statuses reported in the "cache" field of the result metadata
+const (
+	cacheHit    = "hit"
+	cacheMiss   = "miss"
+	cacheBypass = "bypass"
+)
+
+var (
+	cacheHits   = expvar.NewMap("cache_hits")
+	cacheMisses = expvar.NewMap("cache_misses")
+)
+
+// cachedResponse is the stored form of a Response
+type cachedResponse struct {
+	StatusCode int         `json:"status"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+	StoredAt   time.Time   `json:"stored-at"`
+}
+
+// SetCache puts store in front of the API for the tools cfg gives a TTL.
+// It must be called before the client is shared.
+func (c *Client) SetCache(store cache.Store, cfg *config.CacheConfig) {
+	c.cache, c.cacheCfg = store, cfg
+}
+
+// SetTools records which of the tools are cacheable. Requests of any other
+// tool, including POST tools and one-time checks such as
+// get_verify-security-code, reach the API exactly once per call. It must
+// be called before the client is shared.
+func (c *Client) SetTools(tools []models.Tool) {
+	c.cacheable = map[string]bool{}
+	for _, tool := range tools {
+		if tool.Cacheable {
+			c.cacheable[tool.Definition.Name] = true
+		}
+	}
+}
+
+// CacheTTL returns how long the tool's responses are cached, 0 if they aren't
+func (c *Client) CacheTTL(tool string) time.Duration {
+	if c.cache == nil || !c.cacheable[tool] {
+		return 0
+	}
+	return c.cacheCfg.TTL(tool)
+}
+
+// fetch sends the endpoint request through the response cache. Identical
+// concurrent GET requests, by endpoint, arguments and caller, are coalesced
+// into one in front of the cache. Requests of tools that aren't cacheable
+// skip all of that and are sent exactly once. The metadata reports the cache
+// status and whether the result was shared, it is nil for plain uncached
+// requests.
+func (c *Client) fetch(ctx context.Context, tool string, cfg *config.APIConfig, ep Endpoint, args map[string]any) (*Response, *mcp.Meta, error) {
+	params, err := encodeParams(ep.Params, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ep.Method != http.MethodGet || !c.cacheable[tool] {
+		// Sent once, not even retried
+		resp, err := c.send(ctx, cfg, ep, params)
+		return resp, nil, err
+	}
+	bypass, _ := args[BypassCacheArgument].(bool)
+	key := cacheKey(ctx, tool, cfg, params)
+
+	flightKey := key
+	if bypass {
+		// A bypassing call must not be answered from a call that hit the cache
+		flightKey += ":bypass"
+	}
+	resp, meta, shared, err := c.inflight.do(ctx, flightKey, func(ctx context.Context) (*Response, *mcp.Meta, error) {
+		return c.fetchCached(ctx, tool, key, bypass, cfg, ep, params)
+	})
+	if shared {
+		coalescedCalls.Add(tool, 1)
+		if err == nil {
+			meta = coalescedMeta(meta)
+		}
+	}
+	return resp, meta, err
+}
+
+// fetchCached answers from the cache when the tool is cached and bypass
+// isn't set, otherwise sends the request and caches the response
+func (c *Client) fetchCached(ctx context.Context, tool, key string, bypass bool, cfg *config.APIConfig, ep Endpoint, params url.Values) (*Response, *mcp.Meta, error) {
+	ttl := c.CacheTTL(tool)
+	if ttl == 0 {
+		resp, err := c.do(ctx, cfg, ep, params)
+		return resp, nil, err
+	}
+
+	status := cacheBypass
+	if !bypass {
+		status = cacheMiss
+		if data, _, ok := c.cache.Get(key); ok {
+			var cached cachedResponse
+			err := json.Unmarshal(data, &cached)
+			if err == nil {
+				cacheHits.Add(tool, 1)
+				resp := &Response{StatusCode: cached.StatusCode, Header: cached.Header, Body: cached.Body}
+				return resp, cacheMeta(cacheHit, time.Since(cached.StoredAt)), nil
+			}
+			log.Printf("Ignoring unreadable cache entry for %s: %v", tool, err)
+		}
+		cacheMisses.Add(tool, 1)
+	}
+
+	resp, err := c.do(ctx, cfg, ep, params)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := json.Marshal(cachedResponse{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       resp.Body,
+		StoredAt:   time.Now(),
+	})
+	if err == nil {
+		c.cache.Set(key, data, ttl)
+	}
+	return resp, cacheMeta(status, 0), nil
+}
+
+// cacheKey identifies a response by tool, caller and upstream parameters.
+// Callers are told apart by tenant, or by their credentials when there is
+// none, so nobody is served a response paid for by someone else. The
+// credentials are hashed and never stored.
+func cacheKey(ctx context.Context, tool string, cfg *config.APIConfig, params url.Values) string {
+	h := sha256.New()
+	if t, ok := config.TenantFromContext(ctx); ok {
+		h.Write([]byte("tenant\x00" + t.Name + "\x00"))
+	} else {
+		h.Write([]byte("credentials\x00" + cfg.UserID + "\x00" + cfg.APIKey + "\x00" + cfg.BearerToken + "\x00" + cfg.BasicAuth + "\x00"))
+	}
+	// url.Values.Encode sorts by name, so argument order doesn't matter
+	h.Write([]byte(cfg.BaseURL + "\x00" + params.Encode()))
+	return tool + ":" + hex.EncodeToString(h.Sum(nil))
+}
+
+// cacheMeta builds the result metadata, with the age in seconds of hits
+func cacheMeta(status string, age time.Duration) *mcp.Meta {
+	fields := map[string]any{"cache": status}
+	if status == cacheHit {
+		fields["cache-age"] = int(age.Seconds())
+	}
+	return mcp.NewMetaFromMap(fields)
+}
+
+// AddBypassCacheArgument declares the no-cache argument on a tool
+func AddBypassCacheArgument(tool *mcp.Tool) {
+	if tool.InputSchema.Properties == nil {
+		tool.InputSchema.Properties = map[string]any{}
+	}
+	tool.InputSchema.Properties[BypassCacheArgument] = map[string]any{
+		"type":        "boolean",
+		"description": "Skip the response cache and do a fresh lookup",
+	}
+}
@@ -0,0 +1,208 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/neutrino-api/mcp-server/cache"
+	"github.com/neutrino-api/mcp-server/config"
+)
+
+// Endpoint describes a Neutrino API operation exposed as a tool
+type Endpoint struct {
+	Method string   // HTTP method, GET or POST
+	Path   string   // Path relative to the base URL, e.g. "/ip-info"
+	Params []string // Tool arguments forwarded to the API, anything else is handled locally
+	Accept string   // Accept header, defaults to application/json
+}
+
+// Response is a fully read upstream response
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// StatusError is returned by Do when the API responds with a status >= 400
+type StatusError struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("API error: %s", e.Body)
+}
+
+// Client sends tool requests to the Neutrino API. One Client is shared by
+// every tool so connections are pooled across calls.
+type Client struct {
+	httpClient *http.Client
+	retry      config.RetryConfig
+	decodeMode config.DecodeMode
+	// sleep waits between retry attempts, replaceable so retries can be
+	// exercised without real delays
+	sleep func(ctx context.Context, d time.Duration) error
+
+	cache    cache.Store         // Response cache, nil when caching is disabled
+	cacheCfg *config.CacheConfig // Per tool TTLs of the response cache
+	inflight flightGroup         // Identical GET requests in progress
+	// cacheable are the tools whose requests may be cached, coalesced and
+	// retried, see models.Tool.Cacheable
+	cacheable map[string]bool
+}
+
+// New creates a Client with its own tuned transport
+func New(cfg *config.ClientConfig) *Client {
+	dialer := &net.Dialer{
+		Timeout:   cfg.DialTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		TLSHandshakeTimeout:   cfg.TLSHandshakeTimeout,
+		IdleConnTimeout:       cfg.IdleConnTimeout,
+		MaxIdleConns:          cfg.MaxIdleConns,
+		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       cfg.MaxConnsPerHost,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+	return &Client{
+		httpClient: &http.Client{
+			Timeout:   cfg.Timeout,
+			Transport: transport,
+		},
+		retry:      cfg.Retry,
+		decodeMode: cfg.DecodeMode,
+		sleep:      sleepContext,
+	}
+}
+
+var defaultClient atomic.Pointer[Client]
+
+func init() {
+	defaultClient.Store(New(config.DefaultClientConfig()))
+}
+
+// Default returns the shared client used by the tool handlers
+func Default() *Client {
+	return defaultClient.Load()
+}
+
+// SetDefault replaces the shared client, normally once at startup
+func SetDefault(c *Client) {
+	defaultClient.Store(c)
+}
+
+// Do sends the endpoint request with the given tool arguments and reads the
+// whole response. The request is bound to ctx so a cancelled tool call aborts
+// the upstream request too. Idempotent GET requests are retried on transient
+// failures according to the client's retry policy.
+func (c *Client) Do(ctx context.Context, cfg *config.APIConfig, ep Endpoint, args map[string]any) (*Response, error) {
+	params, err := encodeParams(ep.Params, args)
+	if err != nil {
+		return nil, err
+	}
+	return c.do(ctx, cfg, ep, params)
+}
+
+// do is Do for already encoded parameters
+func (c *Client) do(ctx context.Context, cfg *config.APIConfig, ep Endpoint, params url.Values) (*Response, error) {
+	attempts := 1
+	if ep.Method == http.MethodGet && c.retry.MaxAttempts > 1 {
+		// POST endpoints such as sms-verify and phone-verify cost money and
+		// contact real people, so they are never repeated
+		attempts = c.retry.MaxAttempts
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.send(ctx, cfg, ep, params)
+		if err == nil || attempt >= attempts {
+			return resp, err
+		}
+		retry, retryAfter := retryable(err)
+		if !retry {
+			return nil, err
+		}
+		delay := c.backoff(attempt)
+		if retryAfter > 0 {
+			if retryAfter > c.retry.MaxDelay {
+				// The server wants us to wait longer than we are willing to
+				return nil, err
+			}
+			delay = max(delay, retryAfter)
+		}
+		log.Printf("Retrying %s %s in %v (attempt %d/%d): %v", ep.Method, ep.Path, delay, attempt+1, attempts, err)
+		if err := c.sleep(ctx, delay); err != nil {
+			return nil, fmt.Errorf("Request failed: %w", err)
+		}
+	}
+}
+
+// send performs a single attempt of an endpoint request
+func (c *Client) send(ctx context.Context, cfg *config.APIConfig, ep Endpoint, params url.Values) (*Response, error) {
+	endpoint := strings.TrimRight(cfg.BaseURL, "/") + ep.Path
+
+	var body io.Reader
+	if ep.Method == http.MethodGet {
+		if len(params) > 0 {
+			endpoint += "?" + params.Encode()
+		}
+	} else {
+		body = strings.NewReader(params.Encode())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, ep.Method, endpoint, body)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create request: %w", err)
+	}
+	setAuthHeaders(req, cfg)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	accept := ep.Accept
+	if accept == "" {
+		accept = "application/json"
+	}
+	req.Header.Set("Accept", accept)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		return nil, &StatusError{StatusCode: resp.StatusCode, Header: resp.Header, Body: respBody}
+	}
+	return &Response{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       respBody,
+	}, nil
+}
+
+// setAuthHeaders sets both credentials required by the API security schemes
+func setAuthHeaders(req *http.Request, cfg *config.APIConfig) {
+	if cfg.UserID != "" {
+		req.Header.Set("user-id", cfg.UserID)
+	}
+	if cfg.APIKey != "" {
+		req.Header.Set("api-key", cfg.APIKey)
+	}
+}
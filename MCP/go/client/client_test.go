@@ -0,0 +1,259 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/neutrino-api/mcp-server/config"
+	"github.com/neutrino-api/mcp-server/models"
+)
+
+// failure is one scripted response of the stand-in API
+type failure func(w http.ResponseWriter, r *http.Request)
+
+func status(code int, retryAfter string) failure {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if retryAfter != "" {
+			w.Header().Set("Retry-After", retryAfter)
+		}
+		w.WriteHeader(code)
+		w.Write([]byte(`{"api-error":0}`))
+	}
+}
+
+// reset drops the connection with a TCP RST instead of answering
+func reset(w http.ResponseWriter, r *http.Request) {
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		panic(err)
+	}
+	conn.(*net.TCPConn).SetLinger(0)
+	conn.Close()
+}
+
+func ok(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte(`{"valid":true}`))
+}
+
+// standIn is a local API that answers with the scripted responses in turn,
+// then with ok, and counts the requests it received
+type standIn struct {
+	*httptest.Server
+	mu       sync.Mutex
+	script   []failure
+	requests int
+}
+
+func newStandIn(t *testing.T, script ...failure) *standIn {
+	s := &standIn{script: script}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		respond := failure(ok)
+		if s.requests < len(s.script) {
+			respond = s.script[s.requests]
+		}
+		s.requests++
+		s.mu.Unlock()
+		respond(w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *standIn) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// testClient returns a client for the stand-in that records the retry
+// delays instead of sleeping
+func testClient(retry config.RetryConfig) (*Client, *[]time.Duration) {
+	cfg := config.DefaultClientConfig()
+	cfg.Retry = retry
+	c := New(cfg)
+	// A fresh connection per attempt, so the transport never replays a
+	// request on its own after a reset
+	c.httpClient.Transport.(*http.Transport).DisableKeepAlives = true
+	var delays []time.Duration
+	c.sleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return ctx.Err()
+	}
+	return c, &delays
+}
+
+var testRetry = config.RetryConfig{
+	MaxAttempts:  5,
+	InitialDelay: 100 * time.Millisecond,
+	MaxDelay:     5 * time.Second,
+}
+
+var (
+	emailValidate = Endpoint{Method: http.MethodGet, Path: "/email-validate", Params: []string{"email"}}
+	smsVerify     = Endpoint{Method: http.MethodPost, Path: "/sms-verify", Params: []string{"number"}}
+)
+
+func TestDoRetriesTransientFailures(t *testing.T) {
+	retryAt := time.Now().Add(4 * time.Second).UTC().Format(http.TimeFormat)
+	api := newStandIn(t,
+		status(http.StatusServiceUnavailable, ""),
+		status(http.StatusServiceUnavailable, "2"),
+		status(http.StatusTooManyRequests, retryAt),
+		reset,
+	)
+	c, delays := testClient(testRetry)
+
+	resp, err := c.Do(context.Background(), &config.APIConfig{BaseURL: api.URL}, emailValidate, map[string]any{"email": "a@b.c"})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if string(resp.Body) != `{"valid":true}` {
+		t.Errorf("body = %s", resp.Body)
+	}
+	if got := api.count(); got != 5 {
+		t.Errorf("attempts = %d, want 5", got)
+	}
+	if len(*delays) != 4 {
+		t.Fatalf("delays = %v, want 4", *delays)
+	}
+	d := *delays
+	if d[0] != 100*time.Millisecond {
+		t.Errorf("first backoff = %v, want 100ms", d[0])
+	}
+	if d[1] != 2*time.Second {
+		t.Errorf("Retry-After seconds: delay = %v, want 2s", d[1])
+	}
+	// HTTP dates have a resolution of a second
+	if d[2] < 2*time.Second || d[2] > 4*time.Second {
+		t.Errorf("Retry-After date: delay = %v, want about 4s", d[2])
+	}
+	if d[3] != 800*time.Millisecond {
+		t.Errorf("backoff after reset = %v, want 800ms", d[3])
+	}
+}
+
+func TestDoGivesUpAfterMaxAttempts(t *testing.T) {
+	api := newStandIn(t, status(503, ""), status(503, ""), status(503, ""), status(503, ""), status(503, ""), status(503, ""))
+	c, delays := testClient(config.RetryConfig{MaxAttempts: 3, InitialDelay: 100 * time.Millisecond, MaxDelay: time.Second})
+
+	_, err := c.Do(context.Background(), &config.APIConfig{BaseURL: api.URL}, emailValidate, nil)
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("err = %v, want the last 503", err)
+	}
+	if got := api.count(); got != 3 {
+		t.Errorf("attempts = %d, want 3", got)
+	}
+	if len(*delays) != 2 {
+		t.Errorf("delays = %v, want 2", *delays)
+	}
+}
+
+func TestDoRetryAfterBeyondMaxDelay(t *testing.T) {
+	api := newStandIn(t, status(http.StatusServiceUnavailable, "60"))
+	c, delays := testClient(testRetry)
+
+	if _, err := c.Do(context.Background(), &config.APIConfig{BaseURL: api.URL}, emailValidate, nil); err == nil {
+		t.Fatal("Do succeeded, want the 503")
+	}
+	if got := api.count(); got != 1 {
+		t.Errorf("attempts = %d, want 1", got)
+	}
+	if len(*delays) != 0 {
+		t.Errorf("delays = %v, want none", *delays)
+	}
+}
+
+func TestDoNeverRepeatsPOST(t *testing.T) {
+	for name, respond := range map[string]failure{
+		"503":         status(http.StatusServiceUnavailable, ""),
+		"retry-after": status(http.StatusTooManyRequests, "1"),
+		"reset":       reset,
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := newStandIn(t, respond)
+			c, delays := testClient(testRetry)
+
+			if _, err := c.Do(context.Background(), &config.APIConfig{BaseURL: api.URL}, smsVerify, map[string]any{"number": "+447522123456"}); err == nil {
+				t.Fatal("Do succeeded, want the failure")
+			}
+			if got := api.count(); got != 1 {
+				t.Errorf("sms-verify sent %d times, want once", got)
+			}
+			if len(*delays) != 0 {
+				t.Errorf("delays = %v, want none", *delays)
+			}
+		})
+	}
+}
+
+func TestFetchSendsUncacheableToolsOnce(t *testing.T) {
+	verify := Endpoint{Method: http.MethodGet, Path: "/verify-security-code", Params: []string{"security-code"}}
+	api := newStandIn(t, status(http.StatusServiceUnavailable, ""), status(http.StatusServiceUnavailable, ""))
+	c, delays := testClient(testRetry)
+	c.SetTools([]models.Tool{
+		{Definition: mcp.NewTool("get_email-validate"), Cacheable: true},
+		{Definition: mcp.NewTool("get_verify-security-code")},
+	})
+	cfg := &config.APIConfig{BaseURL: api.URL}
+
+	if _, _, err := c.fetch(context.Background(), "get_verify-security-code", cfg, verify, map[string]any{"security-code": "123456"}); err == nil {
+		t.Fatal("fetch succeeded, want the failure")
+	}
+	if got := api.count(); got != 1 || len(*delays) != 0 {
+		t.Errorf("verify-security-code sent %d times after %v, want once", got, *delays)
+	}
+
+	// A cacheable lookup is still retried
+	if _, _, err := c.fetch(context.Background(), "get_email-validate", cfg, emailValidate, map[string]any{"email": "a@b.c"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := api.count(); got != 3 {
+		t.Errorf("%d requests in all, want 3", got)
+	}
+}
+
+func TestBackoffCap(t *testing.T) {
+	c, _ := testClient(config.RetryConfig{MaxAttempts: 20, InitialDelay: 100 * time.Millisecond, MaxDelay: time.Second})
+	want := []time.Duration{100, 200, 400, 800, 1000, 1000, 1000}
+	for i, w := range want {
+		if got := c.backoff(i + 1); got != w*time.Millisecond {
+			t.Errorf("backoff(%d) = %v, want %v", i+1, got, w*time.Millisecond)
+		}
+	}
+	if got := c.backoff(64); got != time.Second {
+		t.Errorf("backoff(64) = %v, want the 1s cap", got)
+	}
+
+	c.retry.Jitter = 0.5
+	for attempt := 1; attempt < 30; attempt++ {
+		if got := c.backoff(attempt); got > time.Second || got < 50*time.Millisecond {
+			t.Errorf("backoff(%d) with jitter = %v, outside [50ms, 1s]", attempt, got)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	for value, want := range map[string]time.Duration{
+		"":                              0,
+		"3":                             3 * time.Second,
+		"0":                             0,
+		"-1":                            0,
+		"soon":                          0,
+		"Sun, 18 Oct 2026 12:00:30 GMT": 30 * time.Second,
+		"Sun, 18 Oct 2026 11:59:00 GMT": 0,
+	} {
+		if got := parseRetryAfter(value, now); got != want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
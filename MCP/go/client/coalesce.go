@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"maps"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// coalescedCalls counts per tool the calls that shared another call's
+// upstream request instead of sending their own
+var coalescedCalls = expvar.NewMap("coalesced_calls")
+
+// flightGroup collapses identical concurrent requests into one
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flight
+}
+
+// flight is an upstream request shared by all callers waiting for it
+type flight struct {
+	done    chan struct{}
+	resp    *Response
+	meta    *mcp.Meta
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// do runs fn once for all concurrent callers with the same key and hands
+// each the shared result; shared reports whether another caller started it.
+// fn isn't bound to any one caller's context, so the first caller giving up
+// doesn't fail the others. It is cancelled once every caller has given up.
+func (g *flightGroup) do(ctx context.Context, key string, fn func(ctx context.Context) (*Response, *mcp.Meta, error)) (resp *Response, meta *mcp.Meta, shared bool, err error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = map[string]*flight{}
+	}
+	f, shared := g.calls[key]
+	if shared {
+		f.waiters++
+	} else {
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		f = &flight{done: make(chan struct{}), waiters: 1, cancel: cancel}
+		g.calls[key] = f
+		go func() {
+			f.resp, f.meta, f.err = fn(callCtx)
+			g.mu.Lock()
+			if g.calls[key] == f {
+				delete(g.calls, key)
+			}
+			g.mu.Unlock()
+			cancel()
+			close(f.done)
+		}()
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.resp, f.meta, shared, f.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		f.waiters--
+		if f.waiters == 0 {
+			f.cancel()
+			// Later callers must not join a cancelled request
+			if g.calls[key] == f {
+				delete(g.calls, key)
+			}
+		}
+		g.mu.Unlock()
+		return nil, nil, shared, fmt.Errorf("Request failed: %w", ctx.Err())
+	}
+}
+
+// coalescedMeta marks a shared result in a copy of its metadata
+func coalescedMeta(meta *mcp.Meta) *mcp.Meta {
+	fields := map[string]any{}
+	if meta != nil {
+		maps.Copy(fields, meta.AdditionalFields)
+	}
+	fields["coalesced"] = true
+	return mcp.NewMetaFromMap(fields)
+}
@@ -0,0 +1,256 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/neutrino-api/mcp-server/config"
+	"github.com/neutrino-api/mcp-server/models"
+)
+
+// blockingAPI answers every request with ok once released, and reports
+// requests arriving and requests abandoned by the client
+type blockingAPI struct {
+	*httptest.Server
+	arrived chan struct{}
+	aborted chan struct{}
+	release chan struct{}
+
+	mu       sync.Mutex
+	requests int
+}
+
+func newBlockingAPI(t *testing.T) *blockingAPI {
+	api := &blockingAPI{
+		arrived: make(chan struct{}, 10),
+		aborted: make(chan struct{}, 10),
+		release: make(chan struct{}),
+	}
+	api.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		api.requests++
+		api.mu.Unlock()
+		api.arrived <- struct{}{}
+		select {
+		case <-api.release:
+			ok(w, r)
+		case <-r.Context().Done():
+			api.aborted <- struct{}{}
+		}
+	}))
+	t.Cleanup(api.Close)
+	return api
+}
+
+func (api *blockingAPI) count() int {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	return api.requests
+}
+
+// wait fails the test unless ch receives within a few seconds
+func wait(t *testing.T, ch <-chan struct{}, what string) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for %s", what)
+	}
+}
+
+// waitForWaiters waits until the flights in progress have n callers
+func waitForWaiters(t *testing.T, g *flightGroup, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		g.mu.Lock()
+		waiters := 0
+		for _, f := range g.calls {
+			waiters += f.waiters
+		}
+		g.mu.Unlock()
+		if waiters == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d callers waiting, want %d", waiters, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func coalescedCount(tool string) int64 {
+	if v, ok := coalescedCalls.Get(tool).(*expvar.Int); ok {
+		return v.Value()
+	}
+	return 0
+}
+
+func coalescingClient() *Client {
+	c, _ := testClient(testRetry)
+	c.SetTools([]models.Tool{{Definition: mcp.NewTool("get_email-validate"), Cacheable: true}})
+	return c
+}
+
+type fetchResult struct {
+	resp *Response
+	meta *mcp.Meta
+	err  error
+}
+
+func TestFetchCoalescesIdenticalCalls(t *testing.T) {
+	// One caller gives up before the response arrives: either the one whose
+	// call started the request or one that joined it
+	for name, leaver := range map[string]int{"first caller leaves": 0, "last caller leaves": 4} {
+		t.Run(name, func(t *testing.T) {
+			testCoalescing(t, 5, leaver)
+		})
+	}
+}
+
+func testCoalescing(t *testing.T, callers, leaver int) {
+	api := newBlockingAPI(t)
+	c := coalescingClient()
+	cfg := &config.APIConfig{BaseURL: api.URL}
+	args := map[string]any{"email": "a@b.c"}
+	before := coalescedCount("get_email-validate")
+
+	leaving, leave := context.WithCancel(context.Background())
+	defer leave()
+	results := make([]fetchResult, callers)
+	var wg sync.WaitGroup
+	for i := range callers {
+		ctx := context.Background()
+		if i == leaver {
+			ctx = leaving
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, meta, err := c.fetch(ctx, "get_email-validate", cfg, emailValidate, args)
+			results[i] = fetchResult{resp, meta, err}
+		}()
+		if i == 0 {
+			wait(t, api.arrived, "the upstream request")
+		}
+	}
+	waitForWaiters(t, &c.inflight, callers)
+
+	leave()
+	waitForWaiters(t, &c.inflight, callers-1)
+	close(api.release)
+	wg.Wait()
+
+	if got := api.count(); got != 1 {
+		t.Errorf("upstream requests = %d, want 1", got)
+	}
+	for i, r := range results {
+		if i == leaver {
+			if !errors.Is(r.err, context.Canceled) {
+				t.Errorf("leaving caller: err = %v, want %v", r.err, context.Canceled)
+			}
+			continue
+		}
+		if r.err != nil {
+			t.Errorf("caller %d: %v", i, r.err)
+			continue
+		}
+		if string(r.resp.Body) != `{"valid":true}` {
+			t.Errorf("caller %d: body = %s", i, r.resp.Body)
+		}
+		coalesced := r.meta != nil && r.meta.AdditionalFields["coalesced"] == true
+		if coalesced != (i > 0) {
+			t.Errorf("caller %d: coalesced = %v", i, coalesced)
+		}
+	}
+	// Every caller but the first shared its request, even one that left
+	if got := coalescedCount("get_email-validate") - before; got != int64(callers-1) {
+		t.Errorf("coalesced_calls grew by %d, want %d", got, callers-1)
+	}
+}
+
+func TestFetchCancelsOnceAllCallersLeave(t *testing.T) {
+	api := newBlockingAPI(t)
+	c := coalescingClient()
+	cfg := &config.APIConfig{BaseURL: api.URL}
+	args := map[string]any{"email": "a@b.c"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, _, err := c.fetch(ctx, "get_email-validate", cfg, emailValidate, args)
+			errs <- err
+		}()
+	}
+	wait(t, api.arrived, "the upstream request")
+	waitForWaiters(t, &c.inflight, 2)
+	cancel()
+	for i := 0; i < 2; i++ {
+		if err := <-errs; !errors.Is(err, context.Canceled) {
+			t.Errorf("err = %v, want %v", err, context.Canceled)
+		}
+	}
+
+	// Started before the cancelled request has necessarily ended, this call
+	// must send its own instead of sharing the cancelled one's failure
+	late := make(chan fetchResult, 1)
+	go func() {
+		resp, meta, err := c.fetch(context.Background(), "get_email-validate", cfg, emailValidate, args)
+		late <- fetchResult{resp, meta, err}
+	}()
+	wait(t, api.aborted, "the upstream request to be cancelled")
+	wait(t, api.arrived, "the late caller's request")
+	close(api.release)
+	r := <-late
+	if r.err != nil {
+		t.Fatalf("late caller: %v", r.err)
+	}
+	if r.meta != nil && r.meta.AdditionalFields["coalesced"] == true {
+		t.Error("late caller joined the cancelled request")
+	}
+	if got := api.count(); got != 2 {
+		t.Errorf("upstream requests = %d, want 2", got)
+	}
+}
+
+func TestFetchNeverCoalescesUncacheableTools(t *testing.T) {
+	api := newBlockingAPI(t)
+	c := coalescingClient()
+	cfg := &config.APIConfig{BaseURL: api.URL}
+	verify := Endpoint{Method: http.MethodGet, Path: "/verify-security-code", Params: []string{"security-code"}}
+
+	var wg sync.WaitGroup
+	for _, tc := range []struct {
+		tool string
+		ep   Endpoint
+	}{
+		{"get_verify-security-code", verify},
+		{"get_verify-security-code", verify},
+		{"get_sms-verify", smsVerify},
+		{"get_sms-verify", smsVerify},
+	} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, _, err := c.fetch(context.Background(), tc.tool, cfg, tc.ep, map[string]any{"security-code": "123456", "number": "+447522123456"}); err != nil {
+				t.Errorf("%s: %v", tc.tool, err)
+			}
+		}()
+	}
+	for i := 0; i < 4; i++ {
+		wait(t, api.arrived, "every request")
+	}
+	close(api.release)
+	wg.Wait()
+	if got := api.count(); got != 4 {
+		t.Errorf("upstream requests = %d, want 4", got)
+	}
+}
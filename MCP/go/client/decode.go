@@ -0,0 +1,82 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"expvar"
+	"fmt"
+
+	"github.com/neutrino-api/mcp-server/config"
+)
+
+// decodeFailures counts responses that didn't decode into the tool's model,
+// keyed by tool name. It is published through expvar as "decode_failures".
+var decodeFailures = expvar.NewMap("decode_failures")
+
+// DecodeFailures returns how often decoding failed for the given tool
+func DecodeFailures(tool string) int64 {
+	if v, ok := decodeFailures.Get(tool).(*expvar.Int); ok {
+		return v.Value()
+	}
+	return 0
+}
+
+// DecodeError reports a response body that doesn't match the tool's model,
+// for example after schema drift or when a proxy answers with an HTML page
+type DecodeError struct {
+	Tool string
+	Err  error
+	Body []byte
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("failed to decode %s response: %v", e.Tool, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// decodeInto decodes body into out. Strict mode also rejects fields the
+// model doesn't know about.
+func (c *Client) decodeInto(tool string, body []byte, out any) error {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	if c.decodeMode == config.DecodeStrict {
+		dec.DisallowUnknownFields()
+	}
+	if err := dec.Decode(out); err != nil {
+		decodeFailures.Add(tool, 1)
+		return &DecodeError{Tool: tool, Err: err, Body: body}
+	}
+	return nil
+}
+
+// withUnknownFields returns result with any top-level fields from body that
+// the model doesn't declare added back, so lenient mode loses no data. The
+// typed result is returned unchanged when there is nothing to add.
+func withUnknownFields(result any, body []byte) any {
+	var raw map[string]json.RawMessage
+	if json.Unmarshal(body, &raw) != nil {
+		return result
+	}
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return result
+	}
+	var merged map[string]json.RawMessage
+	if json.Unmarshal(encoded, &merged) != nil {
+		return result
+	}
+
+	added := false
+	for key, val := range raw {
+		if _, known := merged[key]; !known {
+			merged[key] = val
+			added = true
+		}
+	}
+	if !added {
+		return result
+	}
+	return merged
+}
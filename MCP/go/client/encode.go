@@ -0,0 +1,60 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+)
+
+// encodeParams collects the forwarded tool arguments in endpoint order.
+// Escaping is left to url.Values so values containing '&', '+', '#' or a
+// whole URL reach the API intact.
+func encodeParams(names []string, args map[string]any) (url.Values, error) {
+	params := url.Values{}
+	for _, name := range names {
+		val, ok := args[name]
+		if !ok || val == nil {
+			continue
+		}
+		encoded, err := formatValue(val)
+		if err != nil {
+			return nil, fmt.Errorf("%w %s: %v", errInvalidArgument, name, err)
+		}
+		params.Set(name, encoded)
+	}
+	return params, nil
+}
+
+// formatValue renders a decoded JSON argument the way the API expects it
+func formatValue(val any) (string, error) {
+	switch v := val.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float64:
+		// JSON numbers arrive as float64, render them without exponent so
+		// 1e+06 is sent as 1000000 and 30.0 as 30
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return "", fmt.Errorf("%v is not a valid number", v)
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case json.Number:
+		return v.String(), nil
+	case []any, []string, map[string]any:
+		// Arrays such as browser-bot's exec statements are sent as a JSON array
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(encoded), nil
+	default:
+		return fmt.Sprintf("%v", v), nil
+	}
+}
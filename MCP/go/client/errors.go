@@ -0,0 +1,143 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/neutrino-api/mcp-server/models"
+)
+
+// ErrorCode is the stable error taxonomy reported to agents in tool errors
+type ErrorCode string
+
+const (
+	CodeAuthFailed          ErrorCode = "auth_failed"          // Credentials missing, invalid or not allowed
+	CodeQuotaExceeded       ErrorCode = "quota_exceeded"       // Call, credit or rate limit reached
+	CodeInvalidArgument     ErrorCode = "invalid_argument"     // The tool arguments were rejected
+	CodeUpstreamUnavailable ErrorCode = "upstream_unavailable" // The API could not be reached or failed
+	CodeInvalidResponse     ErrorCode = "invalid_response"     // The response didn't match the tool's model
+	CodeAPIError            ErrorCode = "api_error"            // Any other API error
+	CodeInternal            ErrorCode = "internal"             // The server itself failed, e.g. writing an output file
+)
+
+// apiErrorCodes maps the Neutrino api-error codes whose meaning differs
+// from what the HTTP status alone would suggest
+var apiErrorCodes = map[int]ErrorCode{
+	1:  CodeQuotaExceeded, // Daily API credit limit exceeded
+	2:  CodeAuthFailed,    // Invalid user-id or api-key
+	14: CodeQuotaExceeded, // Per number limit reached for verification calls
+}
+
+// maxErrorSnippet bounds how much of a non-JSON error body is reported
+const maxErrorSnippet = 500
+
+// errInvalidArgument marks argument errors found before calling the API
+var errInvalidArgument = errors.New("invalid argument")
+
+// ToolError is the structured error returned to agents instead of raw text
+type ToolError struct {
+	Code       ErrorCode `json:"code"`
+	Message    string    `json:"message"`
+	APIError   int       `json:"api-error,omitempty"`   // Neutrino api-error code, if the API sent one
+	HTTPStatus int       `json:"http-status,omitempty"` // Upstream HTTP status, 0 if no response was received
+	Retryable  bool      `json:"retryable"`             // Whether repeating the same call may succeed
+	Body       string    `json:"body,omitempty"`        // Start of the offending body for invalid responses
+}
+
+// Error lets a ToolError be passed to ErrorResult as is
+func (e *ToolError) Error() string {
+	return e.Message
+}
+
+// Classify maps an error from Do onto the error taxonomy
+func Classify(err error) ToolError {
+	var known *ToolError
+	if errors.As(err, &known) {
+		return *known
+	}
+	if errors.Is(err, errInvalidArgument) {
+		return ToolError{Code: CodeInvalidArgument, Message: err.Error()}
+	}
+
+	var decodeErr *DecodeError
+	if errors.As(err, &decodeErr) {
+		return ToolError{Code: CodeInvalidResponse, Message: err.Error(), Body: bodySnippet(decodeErr.Body)}
+	}
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		// No response at all, e.g. connection refused or a timeout
+		retry, _ := retryable(err)
+		return ToolError{Code: CodeUpstreamUnavailable, Message: err.Error(), Retryable: retry}
+	}
+
+	toolErr := ToolError{HTTPStatus: statusErr.StatusCode}
+	var apiErr models.APIError
+	if json.Unmarshal(statusErr.Body, &apiErr) == nil && apiErr.Api_error > 0 {
+		toolErr.APIError = apiErr.Api_error
+		toolErr.Message = apiErr.Api_error_msg
+	}
+	if toolErr.Message == "" {
+		toolErr.Message = bodySnippet(statusErr.Body)
+	}
+	if toolErr.Message == "" {
+		toolErr.Message = http.StatusText(statusErr.StatusCode)
+	}
+
+	toolErr.Retryable, _ = retryable(err)
+	if code, ok := apiErrorCodes[toolErr.APIError]; ok {
+		toolErr.Code = code
+		if code == CodeQuotaExceeded && statusErr.StatusCode != http.StatusTooManyRequests {
+			// Credit limits don't reset within a retry window
+			toolErr.Retryable = false
+		}
+		return toolErr
+	}
+
+	switch status := statusErr.StatusCode; {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		toolErr.Code = CodeAuthFailed
+	case status == http.StatusTooManyRequests || status == http.StatusPaymentRequired:
+		toolErr.Code = CodeQuotaExceeded
+	case status >= 500:
+		toolErr.Code = CodeUpstreamUnavailable
+	case status == http.StatusBadRequest || status == http.StatusNotFound || status == http.StatusUnprocessableEntity:
+		toolErr.Code = CodeInvalidArgument
+	default:
+		toolErr.Code = CodeAPIError
+	}
+	return toolErr
+}
+
+// ErrorResult converts an error from Do into a tool error result carrying
+// the classified error as JSON
+func ErrorResult(err error) *mcp.CallToolResult {
+	toolErr := Classify(err)
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	// Keep HTML error pages readable in the body snippet
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if jsonErr := enc.Encode(map[string]ToolError{"error": toolErr}); jsonErr != nil {
+		return mcp.NewToolResultError(toolErr.Message)
+	}
+	return mcp.NewToolResultError(strings.TrimSuffix(buf.String(), "\n"))
+}
+
+// bodySnippet returns the start of a response body for error messages
+func bodySnippet(body []byte) string {
+	snippet := strings.TrimSpace(string(body))
+	if len(snippet) <= maxErrorSnippet {
+		return snippet
+	}
+	snippet = snippet[:maxErrorSnippet]
+	for !utf8.ValidString(snippet) {
+		snippet = snippet[:len(snippet)-1]
+	}
+	return snippet + "..."
+}
@@ -0,0 +1,84 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/neutrino-api/mcp-server/config"
+)
+
+// HandlerFunc is the tool handler signature used by models.Tool
+type HandlerFunc = func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
+
+// RawHandler calls the endpoint, through the response cache for cached
+// tools, and leaves rendering the response to render.
+// Request and API errors are turned into tool errors before render is called.
+// A config attached to ctx (HTTP mode) takes precedence over cfg.
+func RawHandler(cfg *config.APIConfig, ep Endpoint, render func(args map[string]any, resp *Response) *mcp.CallToolResult) HandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]any)
+		if !ok {
+			return ErrorResult(fmt.Errorf("%w: arguments must be an object", errInvalidArgument)), nil
+		}
+		reqCfg := cfg
+		if ctxCfg, ok := config.FromContext(ctx); ok {
+			reqCfg = ctxCfg
+		}
+		resp, meta, err := Default().fetch(ctx, request.Params.Name, reqCfg, ep, args)
+		if err != nil {
+			return ErrorResult(err), nil
+		}
+		result := render(args, resp)
+		if meta != nil {
+			result.Meta = meta
+		}
+		return result, nil
+	}
+}
+
+// JSONHandler decodes the response into T and returns it as pretty-printed
+// JSON. Transforms can adjust the decoded result based on local arguments.
+// Responses that don't decode into T are invalid_response errors, the decode
+// mode decides whether fields T doesn't declare are kept or rejected.
+func JSONHandler[T any](cfg *config.APIConfig, ep Endpoint, transforms ...func(args map[string]any, result *T)) HandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		tool := request.Params.Name
+		return RawHandler(cfg, ep, func(args map[string]any, resp *Response) *mcp.CallToolResult {
+			c := Default()
+			var result T
+			if err := c.decodeInto(tool, resp.Body, &result); err != nil {
+				// Even valid JSON of another shape is an error, as a result
+				// must conform to the output schema the tool declares
+				return ErrorResult(err)
+			}
+			for _, transform := range transforms {
+				transform(args, &result)
+			}
+
+			if c.decodeMode == config.DecodeLenient {
+				return JSONResult(withUnknownFields(result, resp.Body))
+			}
+			return JSONResult(result)
+		})(ctx, request)
+	}
+}
+
+// JSONResult returns result as structured content, with the pretty-printed
+// JSON as text content for clients that predate structured output
+func JSONResult(result any) *mcp.CallToolResult {
+	prettyJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to format JSON", err)
+	}
+	return mcp.NewToolResultStructured(result, string(prettyJSON))
+}
+
+// TextHandler returns the response body as-is, for endpoints such as the
+// CSV downloads that don't respond with JSON
+func TextHandler(cfg *config.APIConfig, ep Endpoint) HandlerFunc {
+	return RawHandler(cfg, ep, func(_ map[string]any, resp *Response) *mcp.CallToolResult {
+		return mcp.NewToolResultText(string(resp.Body))
+	})
+}
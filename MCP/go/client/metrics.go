@@ -0,0 +1,34 @@
+package client
+
+import (
+	"expvar"
+	"fmt"
+	"net/http"
+)
+
+// MetricsHandler serves the client's counters as a JSON object in the
+// format of expvar. Unlike expvar.Handler it leaves out the variables every
+// program publishes, cmdline in particular, which holds any secret passed
+// as a flag.
+func MetricsHandler() http.Handler {
+	metrics := []struct {
+		name string
+		m    *expvar.Map
+	}{
+		{"cache_hits", cacheHits},
+		{"cache_misses", cacheMisses},
+		{"coalesced_calls", coalescedCalls},
+		{"decode_failures", decodeFailures},
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		fmt.Fprint(w, "{\n")
+		for i, metric := range metrics {
+			if i > 0 {
+				fmt.Fprint(w, ",\n")
+			}
+			fmt.Fprintf(w, "%q: %s", metric.name, metric.m.String())
+		}
+		fmt.Fprint(w, "\n}\n")
+	})
+}
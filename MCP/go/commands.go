@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/neutrino-api/mcp-server/config"
+	"github.com/neutrino-api/mcp-server/dataset"
+)
+
+// commands run instead of the server when named by the first argument
+var commands = map[string]func(args []string, stdout io.Writer) error{
+	"blocklist-diff":   blocklistDiffCommand,
+	"blocklist-export": blocklistExportCommand,
+}
+
+// blocklistDiffCommand prints what changed between two stored versions of
+// the ip-blocklist dataset
+func blocklistDiffCommand(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("blocklist-diff", flag.ContinueOnError)
+	configFile := fs.String("config", "", "YAML, TOML or JSON config file to read dataset_dir from")
+	dir := fs.String("dir", "", "Dataset directory (default DATASET_DIR)")
+	from := fs.String("from", "", "The older version (default the one before --to)")
+	to := fs.String("to", "", "The newer version (default the newest)")
+	categories := fs.String("categories", "", "Comma-separated categories to compare (default all)")
+	vpn := fs.Bool("include-vpn", false, "Include addresses only listed as VPN providers")
+	format := fs.String("format", "json", "Output format: json or csv")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s blocklist-diff [flags]\n\n", os.Args[0])
+		fmt.Fprintln(fs.Output(), "Lists the addresses added to and removed from the IP blocklist between two\nversions stored in the dataset directory, per category, as aggregated CIDRs.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	if *format != "json" && *format != "csv" {
+		return fmt.Errorf("invalid --format %q: must be json or csv", *format)
+	}
+	if err := datasetDir(dir, *configFile); err != nil {
+		return err
+	}
+	cats, err := parseCategories(*categories)
+	if err != nil {
+		return err
+	}
+
+	diff, err := dataset.DiffStoredBlocklists(*dir, *from, *to, cats, *vpn, 0)
+	if err != nil {
+		return err
+	}
+	if *format == "csv" {
+		return diff.WriteCSV(stdout)
+	}
+	out, err := json.MarshalIndent(diff, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(stdout, "%s\n", out)
+	return err
+}
+
+// blocklistExportCommand writes a stored version of the ip-blocklist
+// dataset as firewall configuration
+func blocklistExportCommand(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("blocklist-export", flag.ContinueOnError)
+	configFile := fs.String("config", "", "YAML, TOML or JSON config file to read dataset_dir from")
+	dir := fs.String("dir", "", "Dataset directory (default DATASET_DIR)")
+	version := fs.String("version", "", "The version to export (default the newest)")
+	format := fs.String("format", "", "Output format: "+strings.Join(dataset.ExportFormats, ", "))
+	family := fs.String("family", "", "Only export ipv4 or ipv6 addresses (default both, required for iptables)")
+	categories := fs.String("categories", "", "Comma-separated categories to export (default all)")
+	vpn := fs.Bool("include-vpn", false, "Include addresses only listed as VPN providers")
+	name := fs.String("name", dataset.DefaultExportName, "The set or chain name")
+	table := fs.String("table", "filter", "The nftables table holding the sets")
+	output := fs.String("output", "", "File to write, replaced once complete (default stdout)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s blocklist-export --format FORMAT [flags]\n\n", os.Args[0])
+		fmt.Fprintln(fs.Output(), "Writes a version of the IP blocklist stored in the dataset directory as an\nnftables set, ipset restore file, iptables chain or plain CIDR list.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	if *format == "" {
+		return errors.New("--format is required")
+	}
+	if err := datasetDir(dir, *configFile); err != nil {
+		return err
+	}
+	cats, err := parseCategories(*categories)
+	if err != nil {
+		return err
+	}
+	if *version == "" {
+		versions, err := dataset.IPBlocklist.Versions(*dir)
+		if err != nil {
+			return err
+		}
+		if len(versions) == 0 {
+			return fmt.Errorf("no versions of %s stored in %s", dataset.IPBlocklist.Name(), *dir)
+		}
+		*version = versions[0]
+	}
+	blocklist, err := dataset.IPBlocklist.ReadVersion(*dir, *version)
+	if err != nil {
+		return fmt.Errorf("failed to read version %s: %w", *version, err)
+	}
+	opts := dataset.ExportOptions{
+		Format:     *format,
+		Family:     *family,
+		Categories: cats,
+		VPN:        *vpn,
+		Name:       *name,
+		Table:      *table,
+		Version:    *version,
+	}
+	if *output == "" {
+		_, err := dataset.ExportBlocklist(stdout, blocklist, opts)
+		return err
+	}
+
+	// Written beside the output and renamed over it, so a firewall reloading
+	// the file never reads a partial export
+	f, err := os.CreateTemp(filepath.Dir(*output), "."+filepath.Base(*output)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	count, err := dataset.ExportBlocklist(f, blocklist, opts)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(f.Name(), *output); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(stdout, "Wrote %d CIDRs from version %s to %s\n", count, *version, *output)
+	return err
+}
+
+// datasetDir defaults dir to DATASET_DIR, taken from the environment or
+// else configFile
+func datasetDir(dir *string, configFile string) error {
+	if *dir == "" {
+		if configFile != "" {
+			if err := config.LoadFile(configFile); err != nil {
+				return err
+			}
+		}
+		datasetCfg, err := config.LoadDatasetConfig()
+		if err != nil {
+			return err
+		}
+		*dir = datasetCfg.Dir
+	}
+	if *dir == "" {
+		return errors.New("no dataset directory, set --dir, DATASET_DIR or dataset_dir in the --config file")
+	}
+	return nil
+}
+
+func parseCategories(list string) (dataset.Categories, error) {
+	return dataset.ParseCategories(strings.FieldsFunc(list, func(r rune) bool {
+		return r == ',' || r == ' '
+	}))
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AuthConfig configures inbound authentication of MCP clients in HTTP mode
+type AuthConfig struct {
+	Methods []string // Enabled methods tried in order: static, hmac, jwt. Empty disables authentication
+
+	StaticTokens map[string]string // Static client token -> principal
+	HMACSecret   string            // Secret used to verify HMAC-signed tokens
+
+	JWKSFile             string        // Local JWKS file holding the authorization server's signing keys
+	JWTIssuer            string        // Required iss claim
+	JWTAudience          string        // Required aud claim, defaults to ResourceURL
+	JWTTenantClaim       string        // Claim naming the principal's tenant
+	JWTLeeway            time.Duration // Allowed clock skew for exp and nbf
+	RequiredScopes       []string      // Scopes every token must carry
+	ResourceURL          string        // Canonical URL of this MCP server, published in the resource metadata
+	AuthorizationServers []string      // Authorization servers published in the resource metadata
+
+	TenantMap map[string]string // Principal -> tenant, for principals without a tenant of their own
+}
+
+// Enabled reports whether any inbound authentication method is configured
+func (c *AuthConfig) Enabled() bool {
+	return len(c.Methods) > 0
+}
+
+// LoadAuthConfig reads the AUTH_* settings
+func LoadAuthConfig() (*AuthConfig, error) {
+	cfg := &AuthConfig{
+		StaticTokens:         map[string]string{},
+		HMACSecret:           get("AUTH_HMAC_SECRET"),
+		JWKSFile:             get("AUTH_JWKS_FILE"),
+		JWTIssuer:            get("AUTH_JWT_ISSUER"),
+		JWTAudience:          get("AUTH_JWT_AUDIENCE"),
+		JWTTenantClaim:       get("AUTH_JWT_TENANT_CLAIM"),
+		JWTLeeway:            time.Minute,
+		RequiredScopes:       splitList(get("AUTH_REQUIRED_SCOPES"), " ,"),
+		ResourceURL:          strings.TrimRight(get("AUTH_RESOURCE_URL"), "/"),
+		AuthorizationServers: splitList(get("AUTH_AUTHORIZATION_SERVERS"), ","),
+	}
+	if cfg.JWTTenantClaim == "" {
+		cfg.JWTTenantClaim = "tenant"
+	}
+
+	for _, method := range splitList(strings.ToLower(get("AUTH_MODE")), ",") {
+		switch method {
+		case "none":
+		case "static", "hmac", "jwt":
+			cfg.Methods = append(cfg.Methods, method)
+		default:
+			return nil, invalid("AUTH_MODE", method, "must be a list of static, hmac and jwt, or none")
+		}
+	}
+
+	var err error
+	if cfg.StaticTokens, err = parsePairs("AUTH_STATIC_TOKENS", get("AUTH_STATIC_TOKENS"), true); err != nil {
+		return nil, err
+	}
+	if cfg.TenantMap, err = parsePairs("AUTH_TENANT_MAP", get("AUTH_TENANT_MAP"), false); err != nil {
+		return nil, err
+	}
+	if val := get("AUTH_JWT_LEEWAY"); val != "" {
+		if cfg.JWTLeeway, err = time.ParseDuration(val); err != nil {
+			return nil, invalid("AUTH_JWT_LEEWAY", val, err.Error())
+		}
+	}
+	if cfg.JWTAudience == "" {
+		cfg.JWTAudience = cfg.ResourceURL
+	}
+	if len(cfg.AuthorizationServers) == 0 && cfg.JWTIssuer != "" {
+		cfg.AuthorizationServers = []string{cfg.JWTIssuer}
+	}
+
+	for _, method := range cfg.Methods {
+		switch method {
+		case "static":
+			if len(cfg.StaticTokens) == 0 {
+				return nil, fmt.Errorf("%s: static requires AUTH_STATIC_TOKENS", origin("AUTH_MODE"))
+			}
+		case "hmac":
+			if len(cfg.HMACSecret) < 32 {
+				return nil, fmt.Errorf("%s: hmac requires AUTH_HMAC_SECRET of at least 32 characters", origin("AUTH_MODE"))
+			}
+		case "jwt":
+			if cfg.JWKSFile == "" || cfg.JWTIssuer == "" || cfg.ResourceURL == "" {
+				return nil, fmt.Errorf("%s: jwt requires AUTH_JWKS_FILE, AUTH_JWT_ISSUER and AUTH_RESOURCE_URL", origin("AUTH_MODE"))
+			}
+		}
+	}
+
+	return cfg, nil
+}
+
+// parsePairs parses "key=value,key=value" lists. With valueFirst the pairs
+// are written "principal=token" and returned keyed by token.
+func parsePairs(env, raw string, valueFirst bool) (map[string]string, error) {
+	pairs := map[string]string{}
+	for _, entry := range splitList(raw, ",") {
+		key, value, ok := strings.Cut(entry, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || key == "" || value == "" {
+			return nil, fmt.Errorf("invalid %s entry %q: expected name=value", origin(env), entry)
+		}
+		if valueFirst {
+			key, value = value, key
+		}
+		pairs[key] = value
+	}
+	return pairs, nil
+}
+
+// splitList splits raw on any of the separator characters and drops empty entries
+func splitList(raw, separators string) []string {
+	return strings.FieldsFunc(raw, func(r rune) bool {
+		return strings.ContainsRune(separators, r)
+	})
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// DefaultBaseURL is the API server listed in openapi.yaml
+const DefaultBaseURL = "https://neutrinoapi.net"
+
+// BaseURLPolicy decides which API base URLs callers may select through the
+// API_BASE_URL header in HTTP mode. Without it any caller could make the
+// server send requests, with their credentials attached, to any host.
+type BaseURLPolicy struct {
+	Allowed      []string // Permitted base URLs in normalized form
+	Default      string   // Base URL used when the header is absent or ignored
+	IgnoreHeader bool     // Always use Default, whatever the header says
+}
+
+// LoadBaseURLPolicy reads ALLOWED_BASE_URLS (comma-separated) and
+// IGNORE_BASE_URL_HEADER. The server-side API_BASE_URL, if set, is the
+// default and always allowed.
+func LoadBaseURLPolicy(cfg *APIConfig) (*BaseURLPolicy, error) {
+	policy := &BaseURLPolicy{Default: DefaultBaseURL}
+	if cfg.BaseURL != "" {
+		normalized, err := NormalizeBaseURL(cfg.BaseURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", origin("API_BASE_URL"), err)
+		}
+		policy.Default = normalized
+	}
+
+	allowed := get("ALLOWED_BASE_URLS")
+	if allowed == "" {
+		allowed = DefaultBaseURL
+	}
+	for _, entry := range strings.Split(allowed, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		normalized, err := NormalizeBaseURL(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s entry %q: %w", origin("ALLOWED_BASE_URLS"), entry, err)
+		}
+		policy.Allowed = append(policy.Allowed, normalized)
+	}
+	if !slices.Contains(policy.Allowed, policy.Default) {
+		policy.Allowed = append(policy.Allowed, policy.Default)
+	}
+
+	switch val := get("IGNORE_BASE_URL_HEADER"); strings.ToLower(val) {
+	case "", "false", "0", "no":
+	case "true", "1", "yes":
+		policy.IgnoreHeader = true
+	default:
+		return nil, invalid("IGNORE_BASE_URL_HEADER", val, "must be true or false")
+	}
+
+	return policy, nil
+}
+
+// Resolve returns the base URL to use for a request given the value of its
+// API_BASE_URL header, or an error if that base URL isn't allowed
+func (p *BaseURLPolicy) Resolve(requested string) (string, error) {
+	if p.IgnoreHeader || requested == "" {
+		return p.Default, nil
+	}
+	normalized, err := NormalizeBaseURL(requested)
+	if err != nil {
+		return "", err
+	}
+	if !slices.Contains(p.Allowed, normalized) {
+		return "", fmt.Errorf("base URL %s is not allowed", normalized)
+	}
+	return normalized, nil
+}
+
+// NormalizeBaseURL validates a base URL and returns it with a lowercase
+// scheme and host, without the scheme's default port and without a trailing
+// slash, so equivalent spellings compare equal
+func NormalizeBaseURL(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", err
+	}
+	scheme := strings.ToLower(u.Scheme)
+	if scheme != "http" && scheme != "https" {
+		return "", fmt.Errorf("%q must use http or https", raw)
+	}
+	if u.Host == "" || u.User != nil || u.RawQuery != "" || u.Fragment != "" {
+		return "", fmt.Errorf("%q must be a plain scheme://host[:port][/path] URL", raw)
+	}
+	host := strings.ToLower(u.Host)
+	if port := u.Port(); scheme == "http" && port == "80" || scheme == "https" && port == "443" {
+		host = strings.TrimSuffix(host, ":"+port)
+	}
+	return scheme + "://" + host + strings.TrimRight(u.EscapedPath(), "/"), nil
+}
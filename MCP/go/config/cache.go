@@ -0,0 +1,94 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// CacheConfig configures the response cache
+type CacheConfig struct {
+	MaxEntries      int                      // Entries kept in memory, 0 disables the in-memory cache
+	Dir             string                   // Directory of the persistent cache file, empty disables it
+	DiskMaxBytes    int64                    // Size limit of the keys and values in the cache file
+	CompactInterval time.Duration            // How often the cache file is purged and compacted
+	TTLs            map[string]time.Duration // How long each tool's responses are cached, tools not listed aren't cached
+}
+
+// Enabled reports whether either cache is enabled
+func (c *CacheConfig) Enabled() bool {
+	return c.MaxEntries > 0 || c.Dir != ""
+}
+
+// DefaultCacheTTLs are the cache TTLs of lookups whose data changes rarely
+var DefaultCacheTTLs = map[string]time.Duration{
+	"get_bin-lookup":      72 * time.Hour,
+	"get_ip-info":         24 * time.Hour,
+	"get_ua-lookup":       24 * time.Hour,
+	"get_geocode-address": 24 * time.Hour,
+	"get_geocode-reverse": 24 * time.Hour,
+	"get_phone-validate":  24 * time.Hour,
+	"get_domain-lookup":   time.Hour,
+	"get_email-validate":  time.Hour,
+	"get_host-reputation": 15 * time.Minute,
+	"get_ip-blocklist":    5 * time.Minute,
+}
+
+// LoadCacheConfig reads the CACHE_* settings
+func LoadCacheConfig() (*CacheConfig, error) {
+	cfg := &CacheConfig{
+		MaxEntries:      1000,
+		Dir:             get("CACHE_DIR"),
+		DiskMaxBytes:    256 << 20,
+		CompactInterval: time.Hour,
+		TTLs:            map[string]time.Duration{},
+	}
+	for tool, ttl := range DefaultCacheTTLs {
+		cfg.TTLs[tool] = ttl
+	}
+
+	if val := get("CACHE_MAX_ENTRIES"); val != "" {
+		n, err := strconv.Atoi(val)
+		if err != nil || n < 0 {
+			return nil, invalid("CACHE_MAX_ENTRIES", val, "must be a non-negative integer")
+		}
+		cfg.MaxEntries = n
+	}
+
+	if val := get("CACHE_DISK_MAX_MB"); val != "" {
+		n, err := strconv.ParseInt(val, 10, 64)
+		if err != nil || n < 1 {
+			return nil, invalid("CACHE_DISK_MAX_MB", val, "must be a positive integer")
+		}
+		cfg.DiskMaxBytes = n << 20
+	}
+	if val := get("CACHE_COMPACT_INTERVAL"); val != "" {
+		d, err := time.ParseDuration(val)
+		if err != nil || d <= 0 {
+			return nil, invalid("CACHE_COMPACT_INTERVAL", val, "must be a positive duration")
+		}
+		cfg.CompactInterval = d
+	}
+
+	ttls, err := parsePairs("CACHE_TTLS", get("CACHE_TTLS"), false)
+	if err != nil {
+		return nil, err
+	}
+	for tool, val := range ttls {
+		ttl, err := time.ParseDuration(val)
+		if err != nil || ttl < 0 {
+			return nil, fmt.Errorf("invalid %s entry %s=%s: must be a non-negative duration", origin("CACHE_TTLS"), tool, val)
+		}
+		cfg.TTLs[tool] = ttl
+	}
+	return cfg, nil
+}
+
+// TTL returns how long the tool's responses are cached, 0 if they aren't.
+// Whether the tool can be cached at all is up to its models.Tool.Cacheable.
+func (c *CacheConfig) TTL(tool string) time.Duration {
+	if !c.Enabled() {
+		return 0
+	}
+	return c.TTLs[tool]
+}
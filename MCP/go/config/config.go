@@ -2,47 +2,189 @@ package config
 
 import (
 	"fmt"
-	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type APIConfig struct {
 	BaseURL     string
 	BearerToken string // For OAuth2/Bearer authentication
+	UserID      string // Account user ID, sent with the API key
 	APIKey      string // For API key authentication
 	BasicAuth   string // For basic authentication
 	Port        string // For server port configuration
+	OutputDir   string // Directory binary tool output is written to when save-to-file is set
+	Transport   string // MCP transport, empty for STDIO
+	CertFile    string // TLS certificate for the https transports
+	KeyFile     string // TLS key for the https transports
 }
 
+// LoadAPIConfig reads the server settings from flags, environment variables
+// and the config file, in that order of precedence
 func LoadAPIConfig() (*APIConfig, error) {
-	// Check port environment variable (both uppercase and lowercase)
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = os.Getenv("port")
-	}
-	
-	baseURL := os.Getenv("API_BASE_URL")
-	
-	// Check transport environment variable (both uppercase and lowercase)
-	transport := os.Getenv("TRANSPORT")
-	if transport == "" {
-		transport = os.Getenv("transport")
+	port := get("PORT")
+	baseURL := get("API_BASE_URL")
+	transport := get("TRANSPORT")
+
+	switch strings.ToLower(transport) {
+	case "", "stdio":
+	default:
+		if !IsHTTPTransport(transport) {
+			return nil, invalid("TRANSPORT", transport, "must be stdio, http, https, sse or sse-https")
+		}
+		if port != "" {
+			if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+				return nil, invalid("PORT", port, "must be a port number between 1 and 65535")
+			}
+		}
 	}
 	
-	// For STDIO mode (transport is not "http"/"HTTP"/"https"/"HTTPS"), API_BASE_URL is required from environment
-	if transport != "http" && transport != "HTTP" && transport != "https" && transport != "HTTPS" && baseURL == "" {
-		return nil, fmt.Errorf("API_BASE_URL environment variable not set")
+	// For STDIO mode, API_BASE_URL is required
+	if !IsHTTPTransport(transport) && baseURL == "" {
+		return nil, fmt.Errorf("API_BASE_URL not set: set the API_BASE_URL environment variable, the --api-base-url flag or api_base_url in the config file")
 	}
 	
-	// For HTTP/HTTPS mode (transport is "http"/"HTTP"/"https"/"HTTPS"), API_BASE_URL comes from headers
-	// so we don't require it from environment variables
+	// For HTTP/HTTPS/SSE mode, API_BASE_URL comes from headers
+	// so we don't require it
 
 	return &APIConfig{
 		BaseURL:     baseURL,
-		BearerToken: os.Getenv("BEARER_TOKEN"),
-		APIKey:      os.Getenv("API_KEY"),
-		BasicAuth:   os.Getenv("BASIC_AUTH"),
+		BearerToken: get("BEARER_TOKEN"),
+		UserID:      get("USER_ID"),
+		APIKey:      get("API_KEY"),
+		BasicAuth:   get("BASIC_AUTH"),
 		Port:        port,
+		OutputDir:   get("OUTPUT_DIR"),
+		Transport:   transport,
+		CertFile:    get("CERT_FILE"),
+		KeyFile:     get("KEY_FILE"),
 	}, nil
 }
 
+// IsHTTPTransport reports whether transport serves MCP over HTTP (streamable
+// HTTP or SSE, with or without TLS) rather than STDIO
+func IsHTTPTransport(transport string) bool {
+	switch strings.ToLower(transport) {
+	case "http", "https", "sse", "sse-https":
+		return true
+	}
+	return false
+}
+
+// ClientConfig holds the settings of the shared upstream HTTP client
+type ClientConfig struct {
+	Timeout             time.Duration // Overall timeout for a single upstream request
+	DialTimeout         time.Duration // Timeout for establishing a TCP connection
+	TLSHandshakeTimeout time.Duration // Timeout for the TLS handshake
+	IdleConnTimeout     time.Duration // How long idle pooled connections are kept
+	MaxIdleConns        int           // Maximum idle connections across all hosts
+	MaxIdleConnsPerHost int           // Maximum idle connections kept per host
+	MaxConnsPerHost     int           // Maximum connections per host, 0 means no limit
+	Retry               RetryConfig   // Retry policy for idempotent GET requests
+	DecodeMode          DecodeMode    // How responses that don't match the tool's model are handled
+}
+
+// DecodeMode selects how API responses are decoded into the models
+type DecodeMode string
+
+const (
+	// DecodeLenient tolerates unknown fields and keeps them in the result
+	DecodeLenient DecodeMode = "lenient"
+	// DecodeStrict reports unknown fields and type mismatches as errors
+	DecodeStrict DecodeMode = "strict"
+)
+
+// RetryConfig controls how transient upstream failures are retried
+type RetryConfig struct {
+	MaxAttempts  int           // Total attempts including the first, 1 disables retries
+	InitialDelay time.Duration // Delay before the first retry, doubled for every further retry
+	MaxDelay     time.Duration // Upper bound for a single delay, also the longest Retry-After honoured
+	Jitter       float64       // Fraction [0-1] of each delay that is randomised
+}
+
+// DefaultClientConfig returns the client settings used when nothing is configured.
+// The overall timeout is generous because html-render and browser-bot can take minutes.
+func DefaultClientConfig() *ClientConfig {
+	return &ClientConfig{
+		Timeout:             2 * time.Minute,
+		DialTimeout:         10 * time.Second,
+		TLSHandshakeTimeout: 10 * time.Second,
+		IdleConnTimeout:     90 * time.Second,
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 32,
+		MaxConnsPerHost:     0,
+		Retry: RetryConfig{
+			MaxAttempts:  3,
+			InitialDelay: 200 * time.Millisecond,
+			MaxDelay:     5 * time.Second,
+			Jitter:       0.5,
+		},
+		DecodeMode: DecodeLenient,
+	}
+}
+
+func LoadClientConfig() (*ClientConfig, error) {
+	cfg := DefaultClientConfig()
+
+	durations := []struct {
+		env   string
+		field *time.Duration
+	}{
+		{"HTTP_TIMEOUT", &cfg.Timeout},
+		{"HTTP_DIAL_TIMEOUT", &cfg.DialTimeout},
+		{"HTTP_TLS_HANDSHAKE_TIMEOUT", &cfg.TLSHandshakeTimeout},
+		{"HTTP_IDLE_CONN_TIMEOUT", &cfg.IdleConnTimeout},
+		{"HTTP_RETRY_INITIAL_DELAY", &cfg.Retry.InitialDelay},
+		{"HTTP_RETRY_MAX_DELAY", &cfg.Retry.MaxDelay},
+	}
+	for _, d := range durations {
+		if val := get(d.env); val != "" {
+			parsed, err := time.ParseDuration(val)
+			if err != nil {
+				return nil, invalid(d.env, val, err.Error())
+			}
+			*d.field = parsed
+		}
+	}
+
+	ints := []struct {
+		env   string
+		field *int
+	}{
+		{"HTTP_MAX_IDLE_CONNS", &cfg.MaxIdleConns},
+		{"HTTP_MAX_IDLE_CONNS_PER_HOST", &cfg.MaxIdleConnsPerHost},
+		{"HTTP_MAX_CONNS_PER_HOST", &cfg.MaxConnsPerHost},
+		{"HTTP_RETRY_MAX_ATTEMPTS", &cfg.Retry.MaxAttempts},
+	}
+	for _, i := range ints {
+		if val := get(i.env); val != "" {
+			parsed, err := strconv.Atoi(val)
+			if err != nil {
+				return nil, invalid(i.env, val, "must be an integer")
+			}
+			*i.field = parsed
+		}
+	}
 
+	if val := get("HTTP_RETRY_JITTER"); val != "" {
+		parsed, err := strconv.ParseFloat(val, 64)
+		if err != nil || parsed < 0 || parsed > 1 {
+			return nil, invalid("HTTP_RETRY_JITTER", val, "must be a number between 0 and 1")
+		}
+		cfg.Retry.Jitter = parsed
+	}
+	if val := get("DECODE_MODE"); val != "" {
+		switch mode := DecodeMode(strings.ToLower(val)); mode {
+		case DecodeLenient, DecodeStrict:
+			cfg.DecodeMode = mode
+		default:
+			return nil, invalid("DECODE_MODE", val, "must be lenient or strict")
+		}
+	}
+	if cfg.Retry.MaxAttempts < 1 {
+		cfg.Retry.MaxAttempts = 1
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,30 @@
+package config
+
+import "context"
+
+type contextKey struct{}
+
+// WithAPIConfig returns a context carrying the API config for a single
+// request, used in HTTP mode where credentials arrive with each request
+func WithAPIConfig(ctx context.Context, cfg *APIConfig) context.Context {
+	return context.WithValue(ctx, contextKey{}, cfg)
+}
+
+// FromContext returns the request's API config if one was attached
+func FromContext(ctx context.Context) (*APIConfig, bool) {
+	cfg, ok := ctx.Value(contextKey{}).(*APIConfig)
+	return cfg, ok && cfg != nil
+}
+
+type tenantKey struct{}
+
+// WithTenant returns a context carrying the tenant a request runs as
+func WithTenant(ctx context.Context, t *Tenant) context.Context {
+	return context.WithValue(ctx, tenantKey{}, t)
+}
+
+// TenantFromContext returns the request's tenant if one was attached
+func TenantFromContext(ctx context.Context) (*Tenant, bool) {
+	t, ok := ctx.Value(tenantKey{}).(*Tenant)
+	return t, ok && t != nil
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"slices"
+	"strconv"
+	"time"
+)
+
+// DatasetConfig configures the datasets downloaded from the API and held
+// locally for the local_ tools
+type DatasetConfig struct {
+	Names           []string      // Datasets to download, e.g. "bin-list", none when empty
+	BlocklistVPN    bool          // Include IPs only listed as public VPN providers in the ip-blocklist dataset
+	Dir             string        // Directory downloaded versions are kept in, empty keeps them in memory only
+	RefreshInterval time.Duration // How often the datasets are downloaded again
+	KeepVersions    int           // Versions of each dataset kept in Dir, including the one in use
+	MaxSkippedRatio float64       // Fraction of the rows of a download that may fail to parse
+	MinRecordRatio  float64       // Fraction of the current record count a new download must reach
+}
+
+// LoadDatasetConfig reads DATASETS and the dataset download options.
+// Dataset names are checked by the caller, which knows the datasets.
+func LoadDatasetConfig() (*DatasetConfig, error) {
+	cfg := &DatasetConfig{
+		Names:           splitList(get("DATASETS"), ", "),
+		Dir:             get("DATASET_DIR"),
+		RefreshInterval: 24 * time.Hour,
+		KeepVersions:    3,
+		MaxSkippedRatio: 0.01,
+		MinRecordRatio:  0.5,
+	}
+	if val := get("IP_BLOCKLIST_INCLUDE_VPN"); val != "" {
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return nil, invalid("IP_BLOCKLIST_INCLUDE_VPN", val, "must be true or false")
+		}
+		cfg.BlocklistVPN = b
+	}
+	if val := get("DATASET_REFRESH_INTERVAL"); val != "" {
+		d, err := time.ParseDuration(val)
+		if err != nil || d < time.Minute {
+			return nil, invalid("DATASET_REFRESH_INTERVAL", val, "must be a duration of at least 1m")
+		}
+		cfg.RefreshInterval = d
+	}
+	if val := get("DATASET_KEEP_VERSIONS"); val != "" {
+		n, err := strconv.Atoi(val)
+		if err != nil || n < 1 {
+			return nil, invalid("DATASET_KEEP_VERSIONS", val, "must be a positive integer")
+		}
+		cfg.KeepVersions = n
+	}
+
+	ratios := []struct {
+		env   string
+		field *float64
+	}{
+		{"DATASET_MAX_SKIPPED_RATIO", &cfg.MaxSkippedRatio},
+		{"DATASET_MIN_RECORD_RATIO", &cfg.MinRecordRatio},
+	}
+	for _, r := range ratios {
+		if val := get(r.env); val != "" {
+			parsed, err := strconv.ParseFloat(val, 64)
+			if err != nil || parsed < 0 || parsed > 1 {
+				return nil, invalid(r.env, val, "must be a number between 0 and 1")
+			}
+			*r.field = parsed
+		}
+	}
+	return cfg, nil
+}
+
+// Enabled reports whether the named dataset is downloaded. The name "*"
+// matches any dataset.
+func (c *DatasetConfig) Enabled(name string) bool {
+	if name == "*" {
+		return len(c.Names) > 0
+	}
+	return slices.Contains(c.Names, name)
+}
@@ -0,0 +1,340 @@
+package config
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Source is where the value of a setting came from. Flags override
+// environment variables, which override the config file.
+type Source int
+
+const (
+	SourceDefault Source = iota
+	SourceFile
+	SourceEnv
+	SourceFlag
+)
+
+func (s Source) String() string {
+	switch s {
+	case SourceFile:
+		return "file"
+	case SourceEnv:
+		return "env"
+	case SourceFlag:
+		return "flag"
+	}
+	return "default"
+}
+
+// Setting describes one configuration key. Name is the environment
+// variable; the flag is its kebab-case form (--http-timeout) and the config
+// file key its lowercase form (http_timeout).
+type Setting struct {
+	Name    string
+	Usage   string
+	Default string // Shown in --print-config and --help, the loaders apply the actual default
+	Secret  bool   // Redacted by --print-config and never a flag, which ps would show
+}
+
+// Settings lists every configuration key the server reads
+var Settings = []Setting{
+	{Name: "TRANSPORT", Usage: "MCP transport: stdio, http, https, sse or sse-https", Default: "stdio"},
+	{Name: "PORT", Usage: "Port to listen on in HTTP modes"},
+	{Name: "CERT_FILE", Usage: "TLS certificate file for https and sse-https"},
+	{Name: "KEY_FILE", Usage: "TLS key file for https and sse-https"},
+	{Name: "API_BASE_URL", Usage: "Neutrino API base URL, required in STDIO mode"},
+	{Name: "USER_ID", Usage: "Neutrino API user ID"},
+	{Name: "API_KEY", Usage: "Neutrino API key", Secret: true},
+	{Name: "BEARER_TOKEN", Usage: "Bearer token", Secret: true},
+	{Name: "BASIC_AUTH", Usage: "Basic authentication", Secret: true},
+	{Name: "OUTPUT_DIR", Usage: "Directory binary tool output is saved to"},
+	{Name: "ALLOWED_BASE_URLS", Usage: "Comma-separated base URLs clients may select", Default: DefaultBaseURL},
+	{Name: "IGNORE_BASE_URL_HEADER", Usage: "Ignore the API_BASE_URL request header", Default: "false"},
+	{Name: "HTTP_TIMEOUT", Usage: "Overall timeout of an upstream request", Default: "2m"},
+	{Name: "HTTP_DIAL_TIMEOUT", Usage: "Upstream connect timeout", Default: "10s"},
+	{Name: "HTTP_TLS_HANDSHAKE_TIMEOUT", Usage: "Upstream TLS handshake timeout", Default: "10s"},
+	{Name: "HTTP_IDLE_CONN_TIMEOUT", Usage: "How long idle upstream connections are kept", Default: "90s"},
+	{Name: "HTTP_MAX_IDLE_CONNS", Usage: "Maximum idle upstream connections", Default: "100"},
+	{Name: "HTTP_MAX_IDLE_CONNS_PER_HOST", Usage: "Maximum idle upstream connections per host", Default: "32"},
+	{Name: "HTTP_MAX_CONNS_PER_HOST", Usage: "Maximum upstream connections per host, 0 is unlimited", Default: "0"},
+	{Name: "HTTP_RETRY_MAX_ATTEMPTS", Usage: "Attempts per GET request including the first", Default: "3"},
+	{Name: "HTTP_RETRY_INITIAL_DELAY", Usage: "Delay before the first retry", Default: "200ms"},
+	{Name: "HTTP_RETRY_MAX_DELAY", Usage: "Longest delay between retries", Default: "5s"},
+	{Name: "HTTP_RETRY_JITTER", Usage: "Randomised fraction of each retry delay", Default: "0.5"},
+	{Name: "TOOLS_ALLOW", Usage: "Tools to enable by name, glob or category, all when unset"},
+	{Name: "TOOLS_DENY", Usage: "Tools to disable by name, glob or category"},
+	{Name: "DECODE_MODE", Usage: "Response decoding: lenient or strict", Default: string(DecodeLenient)},
+	{Name: "CACHE_MAX_ENTRIES", Usage: "Responses kept in the in-memory cache, 0 disables caching", Default: "1000"},
+	{Name: "CACHE_DIR", Usage: "Directory of the persistent response cache, unset disables it"},
+	{Name: "CACHE_DISK_MAX_MB", Usage: "Size limit of the persistent cache in MiB", Default: "256"},
+	{Name: "CACHE_COMPACT_INTERVAL", Usage: "How often the persistent cache is purged and compacted", Default: "1h"},
+	{Name: "CACHE_TTLS", Usage: "Per tool cache TTLs as tool=duration pairs, 0 disables caching a tool"},
+	{Name: "AUTH_MODE", Usage: "Inbound authentication methods: static, hmac, jwt or none", Default: "none"},
+	{Name: "AUTH_STATIC_TOKENS", Usage: "Static client tokens as principal=token pairs", Secret: true},
+	{Name: "AUTH_HMAC_SECRET", Usage: "Secret verifying HMAC-signed client tokens", Secret: true},
+	{Name: "AUTH_JWKS_FILE", Usage: "JWKS file with the authorization server's keys"},
+	{Name: "AUTH_JWT_ISSUER", Usage: "Required JWT issuer"},
+	{Name: "AUTH_JWT_AUDIENCE", Usage: "Required JWT audience, defaults to AUTH_RESOURCE_URL"},
+	{Name: "AUTH_JWT_TENANT_CLAIM", Usage: "JWT claim naming the tenant", Default: "tenant"},
+	{Name: "AUTH_JWT_LEEWAY", Usage: "Allowed clock skew for JWT exp and nbf", Default: "1m"},
+	{Name: "AUTH_REQUIRED_SCOPES", Usage: "Scopes every JWT must carry"},
+	{Name: "AUTH_RESOURCE_URL", Usage: "Canonical URL of the MCP endpoint"},
+	{Name: "AUTH_AUTHORIZATION_SERVERS", Usage: "Authorization servers published in the resource metadata"},
+	{Name: "AUTH_TENANT_MAP", Usage: "Tenants of principals as principal=tenant pairs"},
+	{Name: "TENANTS_FILE", Usage: "YAML or JSON file with the tenant profiles"},
+	{Name: "DATASETS", Usage: "Datasets downloaded for the local_ tools: bin-list, ip-blocklist"},
+	{Name: "IP_BLOCKLIST_INCLUDE_VPN", Usage: "Include public VPN providers in the ip-blocklist dataset, requires Tier 3", Default: "false"},
+	{Name: "DATASET_DIR", Usage: "Directory downloaded dataset versions are kept in, unset keeps them in memory only"},
+	{Name: "DATASET_REFRESH_INTERVAL", Usage: "How often the datasets are downloaded again", Default: "24h"},
+	{Name: "DATASET_KEEP_VERSIONS", Usage: "Versions of each dataset kept in DATASET_DIR", Default: "3"},
+	{Name: "DATASET_MAX_SKIPPED_RATIO", Usage: "Fraction of the rows of a download that may fail to parse", Default: "0.01"},
+	{Name: "DATASET_MIN_RECORD_RATIO", Usage: "Fraction of the current record count a new download must reach", Default: "0.5"},
+}
+
+// Options are the command-line options that aren't settings
+type Options struct {
+	ConfigFile  string // Config file given with --config
+	PrintConfig bool   // Print the effective config and exit
+}
+
+// layers holds the flag and config file values consulted besides the
+// environment. Until Init runs only the environment is used.
+var layers struct {
+	flags    map[string]string
+	file     map[string]string
+	filePath string
+}
+
+// Init parses the command line and reads the config file it names, making
+// both available to the Load functions
+func Init(args []string) (*Options, error) {
+	opts := &Options{}
+	fs := flag.NewFlagSet(filepath.Base(os.Args[0]), flag.ContinueOnError)
+	fs.StringVar(&opts.ConfigFile, "config", "", "YAML, TOML or JSON config file")
+	fs.BoolVar(&opts.PrintConfig, "print-config", false, "Print the effective config with secrets redacted and exit")
+	values := make(map[string]*string, len(Settings))
+	for _, s := range Settings {
+		if s.Secret {
+			continue
+		}
+		usage := s.Usage
+		if s.Default != "" {
+			usage += " (default " + s.Default + ")"
+		}
+		values[s.Name] = fs.String(flagName(s.Name), "", usage)
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	flags := map[string]string{}
+	fs.Visit(func(f *flag.Flag) {
+		for name, value := range values {
+			if flagName(name) == f.Name {
+				flags[name] = *value
+			}
+		}
+	})
+	layers.flags = flags
+
+	if opts.ConfigFile != "" {
+		if err := LoadFile(opts.ConfigFile); err != nil {
+			return nil, err
+		}
+	}
+	return opts, nil
+}
+
+// LoadFile reads a config file as the layer below the flags and the
+// environment, for commands that parse their own flags instead of Init
+func LoadFile(path string) error {
+	file, err := readConfigFile(path)
+	if err != nil {
+		return err
+	}
+	layers.file, layers.filePath = file, path
+	return nil
+}
+
+// lookup returns the effective value of a setting and where it came from
+func lookup(name string) (string, Source) {
+	if val, ok := layers.flags[name]; ok {
+		return val, SourceFlag
+	}
+	// Set but empty still counts, clearing a value from the file
+	if val, ok := os.LookupEnv(name); ok {
+		return val, SourceEnv
+	}
+	// TRANSPORT and PORT have always been accepted in lowercase too
+	if name == "TRANSPORT" || name == "PORT" {
+		if val, ok := os.LookupEnv(strings.ToLower(name)); ok {
+			return val, SourceEnv
+		}
+	}
+	if val, ok := layers.file[name]; ok {
+		return val, SourceFile
+	}
+	return "", SourceDefault
+}
+
+// get returns the effective value of a setting
+func get(name string) string {
+	val, _ := lookup(name)
+	return val
+}
+
+// origin names a setting the way it was given, for error messages, e.g.
+// "--port flag" or "port in config.yaml"
+func origin(name string) string {
+	_, src := lookup(name)
+	switch src {
+	case SourceFlag:
+		return "--" + flagName(name) + " flag"
+	case SourceFile:
+		return fileKey(name) + " in " + layers.filePath
+	case SourceEnv:
+		return name + " environment variable"
+	}
+	return name
+}
+
+// invalid reports an invalid setting value together with its origin
+func invalid(name, val, reason string) error {
+	return fmt.Errorf("invalid value %q for %s: %s", val, origin(name), reason)
+}
+
+func flagName(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), "_", "-")
+}
+
+func fileKey(name string) string {
+	return strings.ToLower(name)
+}
+
+// readConfigFile reads a flat config file keyed by the lowercase setting
+// names, picking the format by extension
+func readConfigFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var raw map[string]interface{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".json":
+		err = json.Unmarshal(data, &raw)
+	case ".toml":
+		var tree *toml.Tree
+		if tree, err = toml.LoadBytes(data); err == nil {
+			raw = tree.ToMap()
+		}
+	default:
+		return nil, fmt.Errorf("config file %s: unsupported format %q, use .yaml, .yml, .toml or .json", path, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	known := make(map[string]string, len(Settings))
+	for _, s := range Settings {
+		known[fileKey(s.Name)] = s.Name
+	}
+	values := make(map[string]string, len(raw))
+	for key, val := range raw {
+		name, ok := known[strings.ToLower(key)]
+		if !ok {
+			return nil, fmt.Errorf("config file %s: unknown setting %q", path, key)
+		}
+		str, err := fileValue(val)
+		if err != nil {
+			return nil, fmt.Errorf("config file %s: %s: %w", path, key, err)
+		}
+		values[name] = str
+	}
+	return values, nil
+}
+
+// fileValue converts a config file value into the string form the
+// environment variable would have. Lists become comma-separated and maps
+// become key=value pairs, as AUTH_STATIC_TOKENS and AUTH_TENANT_MAP expect.
+func fileValue(val interface{}) (string, error) {
+	switch v := val.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			str, err := fileValue(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = str
+		}
+		return strings.Join(items, ","), nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		pairs := make([]string, len(keys))
+		for i, key := range keys {
+			str, err := fileValue(v[key])
+			if err != nil {
+				return "", err
+			}
+			pairs[i] = key + "=" + str
+		}
+		return strings.Join(pairs, ","), nil
+	}
+	return "", fmt.Errorf("unsupported value of type %T", val)
+}
+
+// PrintConfig writes the effective settings as YAML, usable as a config
+// file, noting where each value came from. Secrets are redacted.
+func PrintConfig(w io.Writer) {
+	for _, s := range Settings {
+		val, src := lookup(s.Name)
+		if src == SourceDefault {
+			if s.Default == "" {
+				fmt.Fprintf(w, "# %s: (unset)\n", fileKey(s.Name))
+			} else {
+				fmt.Fprintf(w, "%s: %s # default\n", fileKey(s.Name), strconv.Quote(s.Default))
+			}
+			continue
+		}
+		if s.Secret && val != "" {
+			val = "REDACTED"
+		}
+		fmt.Fprintf(w, "%s: %s # %s\n", fileKey(s.Name), strconv.Quote(val), src)
+	}
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// withConfigFile makes a config file with content the file layer, restoring
+// the layers afterwards
+func withConfigFile(t *testing.T, content string) {
+	t.Helper()
+	saved := layers
+	t.Cleanup(func() { layers = saved })
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// unsetenv unsets name for the rest of the test
+func unsetenv(t *testing.T, name string) {
+	t.Helper()
+	t.Setenv(name, "")
+	os.Unsetenv(name)
+}
+
+func TestLookupOrder(t *testing.T) {
+	withConfigFile(t, "api_base_url: https://file.example\ntools_allow: [www]\ntransport: http\n")
+	layers.flags = map[string]string{"TOOLS_ALLOW": "geolocation"}
+
+	unsetenv(t, "API_BASE_URL")
+	if val, src := lookup("API_BASE_URL"); val != "https://file.example" || src != SourceFile {
+		t.Errorf("unset env: lookup = %q, %v, want the file", val, src)
+	}
+	// An empty variable clears the file's value
+	t.Setenv("API_BASE_URL", "")
+	if val, src := lookup("API_BASE_URL"); val != "" || src != SourceEnv {
+		t.Errorf("empty env: lookup = %q, %v, want an empty env value", val, src)
+	}
+	// Flags win over both, an empty one too
+	t.Setenv("TOOLS_ALLOW", "e_commerce")
+	if val, src := lookup("TOOLS_ALLOW"); val != "geolocation" || src != SourceFlag {
+		t.Errorf("flag: lookup = %q, %v", val, src)
+	}
+	layers.flags["TOOLS_ALLOW"] = ""
+	if val, src := lookup("TOOLS_ALLOW"); val != "" || src != SourceFlag {
+		t.Errorf("empty flag: lookup = %q, %v", val, src)
+	}
+
+	// The lowercase fallback only applies when TRANSPORT isn't set at all
+	unsetenv(t, "TRANSPORT")
+	t.Setenv("transport", "sse")
+	if val, _ := lookup("TRANSPORT"); val != "sse" {
+		t.Errorf("lowercase transport: lookup = %q, want sse", val)
+	}
+	t.Setenv("TRANSPORT", "")
+	if val, src := lookup("TRANSPORT"); val != "" || src != SourceEnv {
+		t.Errorf("empty TRANSPORT: lookup = %q, %v", val, src)
+	}
+}
+
+func TestInitHasNoSecretFlags(t *testing.T) {
+	saved := layers
+	t.Cleanup(func() { layers = saved })
+	for _, s := range Settings {
+		if !s.Secret {
+			continue
+		}
+		_, err := Init([]string{"--" + flagName(s.Name), "x"})
+		if err == nil || !strings.Contains(err.Error(), "not defined") {
+			t.Errorf("--%s: err = %v, want an undefined flag", flagName(s.Name), err)
+		}
+	}
+	if _, err := Init([]string{"--user-id", "x"}); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Tenant is a server-side credential profile. In HTTP mode an authenticated
+// client's requests use its tenant's base URL and credentials instead of
+// request headers, so Neutrino API secrets never leave the server.
+type Tenant struct {
+	Name    string      `yaml:"name" json:"name"`
+	BaseURL string      `yaml:"base_url" json:"base_url"`
+	UserID  string      `yaml:"user_id" json:"user_id"`
+	APIKey  string      `yaml:"api_key" json:"api_key"`
+	Tools   ToolPolicy  `yaml:"tools" json:"tools"` // Tools the tenant may use, within the server's TOOLS_ALLOW and TOOLS_DENY
+	Quota   TenantQuota `yaml:"quota" json:"quota"`
+}
+
+// TenantQuota limits the tool calls of a tenant, 0 means unlimited
+type TenantQuota struct {
+	RequestsPerMinute int `yaml:"requests_per_minute" json:"requests_per_minute"`
+	RequestsPerDay    int `yaml:"requests_per_day" json:"requests_per_day"`
+}
+
+// ToolEnabled reports whether the tenant may use the tool
+func (t *Tenant) ToolEnabled(name, category string) bool {
+	return t.Tools.Allows(name, category)
+}
+
+// TenantRegistry holds the tenants loaded from the tenants file
+type TenantRegistry struct {
+	tenants map[string]*Tenant
+}
+
+// LoadTenantRegistry reads the tenants file named by TENANTS_FILE. It
+// returns nil when TENANTS_FILE is unset.
+func LoadTenantRegistry() (*TenantRegistry, error) {
+	path := get("TENANTS_FILE")
+	if path == "" {
+		return nil, nil
+	}
+	return LoadTenantRegistryFile(path)
+}
+
+// LoadTenantRegistryFile reads a YAML or JSON tenants file of the form
+//
+//	tenants:
+//	  - name: acme
+//	    user_id: ...
+//	    api_key: ...
+func LoadTenantRegistryFile(path string) (*TenantRegistry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tenants file: %w", err)
+	}
+	var file struct {
+		Tenants []*Tenant `yaml:"tenants"`
+	}
+	// YAML is a superset of JSON, so this reads both
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse tenants file %s: %w", path, err)
+	}
+
+	reg := &TenantRegistry{tenants: make(map[string]*Tenant, len(file.Tenants))}
+	for i, t := range file.Tenants {
+		if t == nil || t.Name == "" {
+			return nil, fmt.Errorf("tenants file %s: tenant %d has no name", path, i)
+		}
+		if _, dup := reg.tenants[t.Name]; dup {
+			return nil, fmt.Errorf("tenants file %s: duplicate tenant %q", path, t.Name)
+		}
+		if t.UserID == "" || t.APIKey == "" {
+			return nil, fmt.Errorf("tenants file %s: tenant %q requires user_id and api_key", path, t.Name)
+		}
+		if t.BaseURL != "" {
+			if t.BaseURL, err = NormalizeBaseURL(t.BaseURL); err != nil {
+				return nil, fmt.Errorf("tenants file %s: tenant %q: %w", path, t.Name, err)
+			}
+		}
+		if err := validatePatterns(t.Tools.Patterns()); err != nil {
+			return nil, fmt.Errorf("tenants file %s: tenant %q: %w", path, t.Name, err)
+		}
+		if t.Quota.RequestsPerMinute < 0 || t.Quota.RequestsPerDay < 0 {
+			return nil, fmt.Errorf("tenants file %s: tenant %q has a negative quota", path, t.Name)
+		}
+		reg.tenants[t.Name] = t
+	}
+	if len(reg.tenants) == 0 {
+		return nil, fmt.Errorf("tenants file %s defines no tenants", path)
+	}
+	return reg, nil
+}
+
+// Lookup returns the named tenant
+func (r *TenantRegistry) Lookup(name string) (*Tenant, bool) {
+	t, ok := r.tenants[name]
+	return t, ok
+}
+
+// Tenants returns all tenants, in no particular order
+func (r *TenantRegistry) Tenants() []*Tenant {
+	tenants := make([]*Tenant, 0, len(r.tenants))
+	for _, t := range r.tenants {
+		tenants = append(tenants, t)
+	}
+	return tenants
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"fmt"
+	"path"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ToolPolicy enables and disables tools. Each pattern is a tool name, a glob
+// such as "get_ip-*", or a category: the name of a tools/ subpackage such as
+// "telephony".
+type ToolPolicy struct {
+	Allow []string `yaml:"allow" json:"allow"` // Only matching tools are enabled, all when empty
+	Deny  []string `yaml:"deny" json:"deny"`   // Matching tools are disabled, even when allowed
+}
+
+// LoadToolPolicy reads TOOLS_ALLOW and TOOLS_DENY
+func LoadToolPolicy() (*ToolPolicy, error) {
+	p := &ToolPolicy{
+		Allow: splitList(get("TOOLS_ALLOW"), ", "),
+		Deny:  splitList(get("TOOLS_DENY"), ", "),
+	}
+	if err := validatePatterns(p.Allow); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", origin("TOOLS_ALLOW"), err)
+	}
+	if err := validatePatterns(p.Deny); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", origin("TOOLS_DENY"), err)
+	}
+	return p, nil
+}
+
+// Allows reports whether the policy enables the tool
+func (p *ToolPolicy) Allows(name, category string) bool {
+	for _, pattern := range p.Deny {
+		if matchTool(pattern, name, category) {
+			return false
+		}
+	}
+	if len(p.Allow) == 0 {
+		return true
+	}
+	for _, pattern := range p.Allow {
+		if matchTool(pattern, name, category) {
+			return true
+		}
+	}
+	return false
+}
+
+// Patterns returns all allow and deny patterns
+func (p *ToolPolicy) Patterns() []string {
+	return append(append([]string{}, p.Allow...), p.Deny...)
+}
+
+// UnmarshalYAML also accepts a plain list, read as the allow list
+func (p *ToolPolicy) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.SequenceNode {
+		p.Deny = nil
+		return node.Decode(&p.Allow)
+	}
+	type plain ToolPolicy
+	return node.Decode((*plain)(p))
+}
+
+func matchTool(pattern, name, category string) bool {
+	if pattern == category || pattern == name {
+		return true
+	}
+	matched, _ := path.Match(pattern, name)
+	return matched
+}
+
+func validatePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("bad pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
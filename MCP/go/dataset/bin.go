@@ -0,0 +1,240 @@
+package dataset
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/neutrino-api/mcp-server/client"
+	"github.com/neutrino-api/mcp-server/models"
+)
+
+// BINs are 6 to 11 digits long
+const (
+	minBINDigits = 6
+	maxBINDigits = 11
+)
+
+// BINList is the BIN database, downloaded with the ISO 3-letter codes and
+// the 8-digit and longer BINs
+var BINList = register(&Dataset[*BINTable]{
+	name: "bin-list",
+	endpoint: client.Endpoint{
+		Method: http.MethodGet,
+		Path:   "/bin-list-download",
+		Params: []string{"include-iso3", "include-8digit"},
+	},
+	files: []File{{Name: "bin-list.csv", Args: map[string]any{"include-iso3": true, "include-8digit": true}}},
+	parse: func(files map[string][]byte) (*BINTable, error) {
+		return ParseBINList(bytes.NewReader(files["bin-list.csv"]))
+	},
+})
+
+// binColumns is the column order of the download, used when it has no
+// header row. The last two are only present with include-iso3.
+var binColumns = []string{
+	"bin-number", "card-brand", "card-type", "card-category", "country",
+	"country-code", "issuer", "issuer-website", "issuer-phone",
+	"country-code3", "currency-code",
+}
+
+// binHeaderAliases maps other spellings of header names onto binColumns
+var binHeaderAliases = map[string]string{
+	"bin":          "bin-number",
+	"iin":          "bin-number",
+	"brand":        "card-brand",
+	"type":         "card-type",
+	"category":     "card-category",
+	"country-name": "country",
+	"issuer-name":  "issuer",
+	"iso3":         "country-code3",
+	"currency":     "currency-code",
+}
+
+// binRecord is one row of the BIN list
+type binRecord struct {
+	brand, cardType, category  string
+	issuer, website, phone     string
+	country, countryCode       string
+	countryCode3, currencyCode string
+	commercial, prepaid        bool
+}
+
+// BINTable indexes the BIN list by BIN for longest-prefix lookups
+type BINTable struct {
+	records map[string]*binRecord
+	skipped int // Rows that couldn't be parsed
+}
+
+// ParseBINList reads the CSV of /bin-list-download. A header row, if
+// present, decides the column order; otherwise binColumns applies. Rows
+// without a valid BIN are skipped.
+func ParseBINList(r io.Reader) (*BINTable, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	cr.ReuseRecord = true
+
+	t := &BINTable{records: map[string]*binRecord{}}
+	columns := map[string]int{}
+	for i, name := range binColumns {
+		columns[name] = i
+	}
+	// The same brands, countries and issuers repeat on many rows
+	strs := map[string]string{}
+	intern := func(s string) string {
+		s = strings.TrimSpace(s)
+		if v, ok := strs[s]; ok {
+			return v
+		}
+		strs[s] = s
+		return s
+	}
+
+	for line := 1; ; line++ {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				t.skipped++
+				continue
+			}
+			return nil, fmt.Errorf("failed to read BIN list: %w", err)
+		}
+		if line == 1 && !isDigits(strings.Trim(row[0], " \ufeff")) {
+			columns = headerColumns(row, binHeaderAliases)
+			if _, ok := columns["bin-number"]; !ok {
+				return nil, errors.New("BIN list header has no BIN column")
+			}
+			continue
+		}
+
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(row) {
+				return ""
+			}
+			return intern(row[i])
+		}
+		bin := strings.Trim(field("bin-number"), " \ufeff")
+		if !isDigits(bin) || len(bin) < minBINDigits || len(bin) > maxBINDigits {
+			t.skipped++
+			continue
+		}
+		if _, ok := t.records[bin]; ok {
+			continue
+		}
+		rec := &binRecord{
+			brand:        field("card-brand"),
+			cardType:     field("card-type"),
+			category:     field("card-category"),
+			issuer:       field("issuer"),
+			website:      field("issuer-website"),
+			phone:        field("issuer-phone"),
+			country:      field("country"),
+			countryCode:  field("country-code"),
+			countryCode3: field("country-code3"),
+			currencyCode: field("currency-code"),
+		}
+		rec.commercial, rec.prepaid = cardFlags(rec.category)
+		if v, err := strconv.ParseBool(field("is-commercial")); err == nil {
+			rec.commercial = v
+		}
+		if v, err := strconv.ParseBool(field("is-prepaid")); err == nil {
+			rec.prepaid = v
+		}
+		t.records[bin] = rec
+	}
+	return t, nil
+}
+
+// Len returns the number of BINs
+func (t *BINTable) Len() int {
+	return len(t.records)
+}
+
+// Skipped returns the number of rows that couldn't be parsed
+func (t *BINTable) Skipped() int {
+	return t.skipped
+}
+
+// Lookup finds the longest BIN that number starts with. number is a BIN or
+// a whole card number, spaces and dashes are ignored. The result is not
+// valid when no BIN matches; the IP fields are never set.
+func (t *BINTable) Lookup(number string) (models.BINLookupResponse, error) {
+	digits := strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(number))
+	if !isDigits(digits) || len(digits) < minBINDigits {
+		return models.BINLookupResponse{}, fmt.Errorf("bin-number must have at least %d digits", minBINDigits)
+	}
+	for n := min(len(digits), maxBINDigits); n >= minBINDigits; n-- {
+		rec, ok := t.records[digits[:n]]
+		if !ok {
+			continue
+		}
+		return models.BINLookupResponse{
+			Valid:          true,
+			Bin_number:     digits[:n],
+			Card_brand:     rec.brand,
+			Card_type:      rec.cardType,
+			Card_category:  rec.category,
+			Issuer:         rec.issuer,
+			Issuer_website: rec.website,
+			Issuer_phone:   rec.phone,
+			Country:        rec.country,
+			Country_code:   rec.countryCode,
+			Country_code3:  rec.countryCode3,
+			Currency_code:  rec.currencyCode,
+			Is_commercial:  rec.commercial,
+			Is_prepaid:     rec.prepaid,
+		}, nil
+	}
+	return models.BINLookupResponse{Bin_number: digits[:min(len(digits), maxBINDigits)]}, nil
+}
+
+// cardFlags derives is-commercial and is-prepaid from the card category,
+// for downloads that don't have those columns
+func cardFlags(category string) (commercial, prepaid bool) {
+	c := strings.ToUpper(category)
+	for _, word := range []string{"BUSINESS", "CORPORATE", "COMMERCIAL", "PURCHASING", "FLEET"} {
+		if strings.Contains(c, word) {
+			commercial = true
+		}
+	}
+	return commercial, strings.Contains(c, "PREPAID")
+}
+
+// headerColumns maps the normalised names of a header row to their index
+func headerColumns(header []string, aliases map[string]string) map[string]int {
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.Trim(name, " \ufeff"))
+		name = strings.NewReplacer(" ", "-", "_", "-").Replace(name)
+		if alias, ok := aliases[name]; ok {
+			name = alias
+		}
+		if _, ok := columns[name]; !ok {
+			columns[name] = i
+		}
+	}
+	return columns
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
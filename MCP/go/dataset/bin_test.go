@@ -0,0 +1,162 @@
+package dataset
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/neutrino-api/mcp-server/models"
+)
+
+// A download with include-iso3 and include-8digit, in binColumns order
+const binListNoHeader = `411111,VISA,CREDIT,CLASSIC,UNITED STATES,US,JPMORGAN CHASE BANK,www.chase.com,1-800-935-9935,USA,USD
+41111122,VISA,DEBIT,BUSINESS PREPAID,CANADA,CA,SOME BANK,,,CAN,CAD
+552000,MASTERCARD,CREDIT,CORPORATE,UNITED KINGDOM,GB,BARCLAYS,,,GBR,GBP
+12345678901,AMEX,CHARGE CARD,PLATINUM,FRANCE,FR,LONG BIN BANK,,,FRA,EUR
+`
+
+func parseBINList(t *testing.T, data string) *BINTable {
+	t.Helper()
+	table, err := ParseBINList(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return table
+}
+
+func lookupBIN(t *testing.T, table *BINTable, number string) models.BINLookupResponse {
+	t.Helper()
+	resp, err := table.Lookup(number)
+	if err != nil {
+		t.Fatalf("Lookup(%s): %v", number, err)
+	}
+	return resp
+}
+
+func TestParseBINListWithoutHeader(t *testing.T) {
+	table := parseBINList(t, binListNoHeader)
+	if table.Len() != 4 || table.Skipped() != 0 {
+		t.Errorf("Len = %d, Skipped = %d, want 4 and 0", table.Len(), table.Skipped())
+	}
+
+	// Every field of the response, the IP ones left unset
+	want := models.BINLookupResponse{
+		Valid:          true,
+		Bin_number:     "411111",
+		Card_brand:     "VISA",
+		Card_type:      "CREDIT",
+		Card_category:  "CLASSIC",
+		Issuer:         "JPMORGAN CHASE BANK",
+		Issuer_website: "www.chase.com",
+		Issuer_phone:   "1-800-935-9935",
+		Country:        "UNITED STATES",
+		Country_code:   "US",
+		Country_code3:  "USA",
+		Currency_code:  "USD",
+	}
+	if got := lookupBIN(t, table, "411111"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Lookup(411111) =\n%+v\nwant\n%+v", got, want)
+	}
+
+	// Flags derived from the category
+	if got := lookupBIN(t, table, "552000"); !got.Is_commercial || got.Is_prepaid {
+		t.Errorf("CORPORATE: commercial %v, prepaid %v", got.Is_commercial, got.Is_prepaid)
+	}
+	if got := lookupBIN(t, table, "41111122"); !got.Is_commercial || !got.Is_prepaid {
+		t.Errorf("BUSINESS PREPAID: commercial %v, prepaid %v", got.Is_commercial, got.Is_prepaid)
+	}
+}
+
+func TestParseBINListWithHeader(t *testing.T) {
+	// Columns in another order, under other names, with flag columns
+	table := parseBINList(t, "\ufeffBIN,Brand,Issuer Name,country_code,Category,is-prepaid,is-commercial,ISO3,Currency\n"+
+		"411111,VISA,CHASE,US,CLASSIC,true,true,USA,USD\n"+
+		"422222,VISA,OTHER,GB,BUSINESS,false,false,,\n")
+	got := lookupBIN(t, table, "411111")
+	if got.Card_brand != "VISA" || got.Issuer != "CHASE" || got.Country_code != "US" || got.Card_category != "CLASSIC" ||
+		got.Country_code3 != "USA" || got.Currency_code != "USD" || got.Card_type != "" {
+		t.Errorf("Lookup(411111) = %+v", got)
+	}
+	// The columns win over the category
+	if !got.Is_prepaid || !got.Is_commercial {
+		t.Errorf("flag columns ignored: %+v", got)
+	}
+	if got := lookupBIN(t, table, "422222"); got.Is_commercial {
+		t.Error("is-commercial column false but the card is commercial")
+	}
+
+	// Without include-iso3 the last two columns are missing
+	table = parseBINList(t, "411111,VISA,CREDIT,CLASSIC,UNITED STATES,US,CHASE,,\n")
+	if got := lookupBIN(t, table, "411111"); !got.Valid || got.Country_code != "US" || got.Country_code3 != "" || got.Currency_code != "" {
+		t.Errorf("without include-iso3: %+v", got)
+	}
+
+	if _, err := ParseBINList(strings.NewReader("brand,issuer\nVISA,CHASE\n")); err == nil {
+		t.Error("header without a BIN column accepted")
+	}
+}
+
+func TestParseBINListSkipsMalformedRows(t *testing.T) {
+	table := parseBINList(t, "411111,VISA\n"+
+		"41111,TOO SHORT\n"+
+		"123456789012,TOO LONG\n"+
+		"41x111,NOT DIGITS\n"+
+		",EMPTY\n"+
+		"411111,DUPLICATE\n"+
+		"433333,MASTERCARD\n")
+	if table.Len() != 2 {
+		t.Errorf("Len = %d, want 2", table.Len())
+	}
+	if table.Skipped() != 4 {
+		t.Errorf("Skipped = %d, want 4", table.Skipped())
+	}
+	// The first of duplicate rows is kept
+	if got := lookupBIN(t, table, "411111"); got.Card_brand != "VISA" {
+		t.Errorf("duplicate BIN: brand %q, want VISA", got.Card_brand)
+	}
+}
+
+func TestBINTableLookup(t *testing.T) {
+	table := parseBINList(t, binListNoHeader)
+	for _, tc := range []struct {
+		number string
+		bin    string // Matched BIN, empty when none matches
+		brand  string
+	}{
+		{"411111", "411111", "VISA"},
+		{"4111 1122 3333 4444", "41111122", "VISA"}, // The 8-digit BIN beats its 6-digit prefix
+		{"4111-1122", "41111122", "VISA"},
+		{"4111 1111 1111 1111", "411111", "VISA"},
+		{"4111119", "411111", "VISA"},
+		{"5520001234567890", "552000", "MASTERCARD"},
+		{"12345678901", "12345678901", "AMEX"},
+		{"1234567890123456", "12345678901", "AMEX"}, // Card numbers longer than a BIN
+		{"123456", "", ""},
+		{"999999", "", ""},
+	} {
+		got := lookupBIN(t, table, tc.number)
+		if tc.bin == "" {
+			if got.Valid {
+				t.Errorf("Lookup(%s) = %+v, want no match", tc.number, got)
+			}
+			continue
+		}
+		if !got.Valid || got.Bin_number != tc.bin || got.Card_brand != tc.brand {
+			t.Errorf("Lookup(%s) = %s %s valid %v, want %s %s", tc.number, got.Bin_number, got.Card_brand, got.Valid, tc.bin, tc.brand)
+		}
+	}
+	if got := lookupBIN(t, table, "41111122"); got.Country_code != "CA" {
+		t.Errorf("8-digit BIN: country %s, want CA", got.Country_code)
+	}
+
+	// An unmatched number reports the BIN digits it was looked up by
+	if got := lookupBIN(t, table, "9999 9999 9999 9999"); got.Bin_number != "99999999999" {
+		t.Errorf("unmatched bin-number = %q", got.Bin_number)
+	}
+
+	for _, number := range []string{"", "41111", "4111 1", "41111a", "4111.11", "+411111"} {
+		if got, err := table.Lookup(number); err == nil {
+			t.Errorf("Lookup(%q) = %+v, want an error", number, got)
+		}
+	}
+}
@@ -0,0 +1,341 @@
+package dataset
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/neutrino-api/mcp-server/client"
+	"github.com/neutrino-api/mcp-server/config"
+	"github.com/neutrino-api/mcp-server/models"
+)
+
+// IPBlocklist is the IP blocklist, downloaded as CSV in CIDR notation for
+// IPv4 and for IPv6
+var IPBlocklist = register(&Dataset[*Blocklist]{
+	name: "ip-blocklist",
+	endpoint: client.Endpoint{
+		Method: http.MethodGet,
+		Path:   "/ip-blocklist-download",
+		Params: []string{"format", "include-vpn", "cidr", "ip6"},
+	},
+	files: []File{
+		{Name: "ipv4.csv", Args: map[string]any{"format": "csv", "cidr": true}},
+		{Name: "ipv6.csv", Args: map[string]any{"format": "csv", "cidr": true, "ip6": true}},
+	},
+	parse: func(files map[string][]byte) (*Blocklist, error) {
+		b := NewBlocklist()
+		for _, name := range []string{"ipv4.csv", "ipv6.csv"} {
+			if err := b.Read(bytes.NewReader(files[name])); err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+		}
+		return b, nil
+	},
+	options: func(cfg *config.DatasetConfig) map[string]any {
+		return map[string]any{"include-vpn": cfg.BlocklistVPN}
+	},
+})
+
+// BlocklistCategories are the blocklist categories, each reported by an
+// is- field of models.IPBlocklistResponse
+var BlocklistCategories = []string{
+	"bot", "exploit-bot", "hijacked", "malware", "proxy", "spam-bot",
+	"spider", "spyware", "tor", "vpn", "dshield",
+}
+
+// Categories is a set of BlocklistCategories
+type Categories uint16
+
+// vpnCategory is left out of lookups unless they ask for VPN providers
+var vpnCategory, _ = ParseCategories([]string{"vpn"})
+
+// ParseCategories returns the set of the named categories
+func ParseCategories(names []string) (Categories, error) {
+	var c Categories
+	for _, name := range names {
+		i := slices.Index(BlocklistCategories, strings.ToLower(strings.TrimSpace(name)))
+		if i < 0 {
+			return 0, fmt.Errorf("unknown blocklist category %q, expected one of %s", name, strings.Join(BlocklistCategories, ", "))
+		}
+		c |= 1 << i
+	}
+	return c, nil
+}
+
+// Has reports whether c contains the named category
+func (c Categories) Has(name string) bool {
+	i := slices.Index(BlocklistCategories, name)
+	return i >= 0 && c&(1<<i) != 0
+}
+
+// Names returns the categories in c in the order of BlocklistCategories
+func (c Categories) Names() []string {
+	names := []string{}
+	for i, name := range BlocklistCategories {
+		if c&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// blockEntry is what the blocklist records about one prefix
+type blockEntry struct {
+	categories Categories
+	listCount  int
+	lastSeen   int64
+}
+
+func (e blockEntry) merge(o blockEntry) blockEntry {
+	return blockEntry{
+		categories: e.categories | o.categories,
+		listCount:  max(e.listCount, o.listCount),
+		lastSeen:   max(e.lastSeen, o.lastSeen),
+	}
+}
+
+// blocklistColumns is the column order of the CSV download, used when it
+// has no header row. The TXT download has the address column only.
+var blocklistColumns = append(append([]string{"ip"}, prefixed("is-", BlocklistCategories)...), "list-count", "last-seen")
+
+// blocklistHeaderAliases maps other spellings of header names onto
+// blocklistColumns
+var blocklistHeaderAliases = map[string]string{
+	"cidr":       "ip",
+	"address":    "ip",
+	"ip-address": "ip",
+	"network":    "ip",
+	"categories": "blocklists",
+}
+
+// Blocklist indexes IPv4 and IPv6 blocklist entries in radix trees for
+// matching addresses against listed ranges
+type Blocklist struct {
+	v4, v6  prefixTree
+	skipped int // Rows that couldn't be parsed
+}
+
+// NewBlocklist returns an empty Blocklist
+func NewBlocklist() *Blocklist {
+	return &Blocklist{}
+}
+
+// Read adds the entries of one /ip-blocklist-download file, CSV or TXT, with
+// single addresses or CIDRs of either address family. A header row, if
+// present, decides the CSV column order; otherwise blocklistColumns applies.
+// Rows without a valid address are skipped.
+func (b *Blocklist) Read(r io.Reader) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	cr.ReuseRecord = true
+	cr.Comment = '#'
+
+	columns := map[string]int{}
+	for i, name := range blocklistColumns {
+		columns[name] = i
+	}
+	for line := 1; ; line++ {
+		row, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				b.skipped++
+				continue
+			}
+			return fmt.Errorf("failed to read IP blocklist: %w", err)
+		}
+		if line == 1 {
+			if _, err := parsePrefix(row[0]); err != nil {
+				columns = headerColumns(row, blocklistHeaderAliases)
+				if _, ok := columns["ip"]; !ok {
+					return errors.New("IP blocklist header has no address column")
+				}
+				continue
+			}
+		}
+
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[i])
+		}
+		p, err := parsePrefix(field("ip"))
+		if err != nil {
+			b.skipped++
+			continue
+		}
+		var e blockEntry
+		for i, name := range BlocklistCategories {
+			if listed, _ := strconv.ParseBool(field("is-" + name)); listed {
+				e.categories |= 1 << i
+			}
+		}
+		if names := field("blocklists"); names != "" {
+			listed, err := ParseCategories(splitNames(names))
+			if err != nil {
+				b.skipped++
+				continue
+			}
+			e.categories |= listed
+		}
+		e.listCount, _ = strconv.Atoi(field("list-count"))
+		e.lastSeen, _ = strconv.ParseInt(field("last-seen"), 10, 64)
+		b.tree(p.Addr()).insert(p, e)
+	}
+}
+
+// Len returns the number of listed addresses and ranges
+func (b *Blocklist) Len() int {
+	return b.v4.size + b.v6.size
+}
+
+// Skipped returns the number of rows that couldn't be parsed
+func (b *Blocklist) Skipped() int {
+	return b.skipped
+}
+
+// Lookup reports whether addr is listed, combining the categories of every
+// listed range containing it; cidr is the most specific of those ranges.
+// Unless vpn is set, addresses only listed as VPN providers are reported as
+// not listed, as by the API without vpn-lookup. Sensors are never set.
+func (b *Blocklist) Lookup(addr netip.Addr, vpn bool) models.IPBlocklistResponse {
+	addr = addr.Unmap()
+	result := models.IPBlocklistResponse{
+		Ip:         addr.String(),
+		Blocklists: []string{},
+		Sensors:    []models.BlocklistSensor{},
+	}
+	var matched *netip.Prefix
+	var entry blockEntry
+	b.tree(addr).lookup(addr, func(p netip.Prefix, e blockEntry) {
+		if !vpn && e.categories == vpnCategory {
+			return
+		}
+		matched, entry = &p, entry.merge(e)
+	})
+	if matched == nil {
+		return result
+	}
+	if !vpn {
+		entry.categories &^= vpnCategory
+	}
+
+	result.Is_listed = true
+	result.Cidr = matched.String()
+	result.Blocklists = entry.categories.Names()
+	result.List_count = max(entry.listCount, len(result.Blocklists), 1)
+	result.Last_seen = int(entry.lastSeen)
+	result.Is_bot = entry.categories.Has("bot")
+	result.Is_exploit_bot = entry.categories.Has("exploit-bot")
+	result.Is_hijacked = entry.categories.Has("hijacked")
+	result.Is_malware = entry.categories.Has("malware")
+	result.Is_proxy = entry.categories.Has("proxy")
+	result.Is_spam_bot = entry.categories.Has("spam-bot")
+	result.Is_spider = entry.categories.Has("spider")
+	result.Is_spyware = entry.categories.Has("spyware")
+	result.Is_tor = entry.categories.Has("tor")
+	result.Is_vpn = entry.categories.Has("vpn")
+	result.Is_dshield = entry.categories.Has("dshield")
+	return result
+}
+
+// Walk calls fn for every listed address or range, IPv4 first, with the
+// categories it is listed in
+func (b *Blocklist) Walk(fn func(p netip.Prefix, categories Categories)) {
+	for _, t := range []*prefixTree{&b.v4, &b.v6} {
+		t.walk(func(p netip.Prefix, e blockEntry) {
+			fn(p, e.categories)
+		})
+	}
+}
+
+// walkVisible is Walk as Lookup sees the blocklist: unless vpn is set,
+// addresses only listed as VPN providers are left out and the vpn category
+// is dropped from the others
+func (b *Blocklist) walkVisible(vpn bool, fn func(p netip.Prefix, categories Categories)) {
+	b.Walk(func(p netip.Prefix, categories Categories) {
+		if !vpn {
+			if categories == vpnCategory {
+				return
+			}
+			categories &^= vpnCategory
+		}
+		fn(p, categories)
+	})
+}
+
+func (b *Blocklist) tree(addr netip.Addr) *prefixTree {
+	if addr.Is4() {
+		return &b.v4
+	}
+	return &b.v6
+}
+
+// ParseIP reads an address the way the ip-blocklist API accepts it: with
+// or without a port, in CIDR notation or as a comma-separated list, of
+// which the first entry is used
+func ParseIP(s string) (netip.Addr, error) {
+	s, _, _ = strings.Cut(strings.TrimSpace(s), ",")
+	s = strings.TrimSpace(s)
+	if addr, err := netip.ParseAddr(s); err == nil {
+		return addr.Unmap(), nil
+	}
+	if addrPort, err := netip.ParseAddrPort(s); err == nil {
+		return addrPort.Addr().Unmap(), nil
+	}
+	if p, err := netip.ParsePrefix(s); err == nil {
+		return p.Addr().Unmap(), nil
+	}
+	return netip.Addr{}, fmt.Errorf("%q is not an IP address", s)
+}
+
+// parsePrefix reads a blocklist entry, an address or a CIDR
+func parsePrefix(s string) (netip.Prefix, error) {
+	s = strings.Trim(s, " \ufeff")
+	if strings.Contains(s, "/") {
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		if p.Addr().Is4In6() && p.Bits() >= 96 {
+			p = netip.PrefixFrom(p.Addr().Unmap(), p.Bits()-96)
+		}
+		return p.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// splitNames splits a list of category names on spaces, commas, semicolons
+// or pipes
+func splitNames(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return strings.ContainsRune(" ,;|", r)
+	})
+}
+
+func prefixed(prefix string, names []string) []string {
+	out := make([]string, len(names))
+	for i, name := range names {
+		out[i] = prefix + name
+	}
+	return out
+}
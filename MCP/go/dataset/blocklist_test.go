@@ -0,0 +1,287 @@
+package dataset
+
+import (
+	"math/rand/v2"
+	"net/netip"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func mustPrefix(t *testing.T, s string) netip.Prefix {
+	t.Helper()
+	p, err := parsePrefix(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// matches returns the prefixes the tree reports for addr, in lookup order
+func matches(tree *prefixTree, addr netip.Addr) []string {
+	out := []string{}
+	tree.lookup(addr, func(p netip.Prefix, _ blockEntry) {
+		out = append(out, p.String())
+	})
+	return out
+}
+
+func TestPrefixTree(t *testing.T) {
+	var tree prefixTree
+	// Inserted so that every case of insert is taken: a new root, a prefix
+	// within a node, one containing a node, a split into a branch node and
+	// a merge into an existing prefix
+	for _, s := range []string{"10.1.2.0/24", "10.1.2.128/25", "10.0.0.0/8", "10.1.3.0/24", "10.1.2.0/24", "0.0.0.0/0", "192.168.0.1"} {
+		tree.insert(mustPrefix(t, s), blockEntry{listCount: 1})
+	}
+	if tree.size != 6 {
+		t.Errorf("size = %d, want 6 distinct prefixes", tree.size)
+	}
+
+	for addr, want := range map[string][]string{
+		"10.1.2.200":  {"0.0.0.0/0", "10.0.0.0/8", "10.1.2.0/24", "10.1.2.128/25"},
+		"10.1.2.1":    {"0.0.0.0/0", "10.0.0.0/8", "10.1.2.0/24"},
+		"10.1.3.255":  {"0.0.0.0/0", "10.0.0.0/8", "10.1.3.0/24"},
+		"10.200.0.0":  {"0.0.0.0/0", "10.0.0.0/8"},
+		"192.168.0.1": {"0.0.0.0/0", "192.168.0.1/32"},
+		"192.168.0.2": {"0.0.0.0/0"},
+	} {
+		if got := matches(&tree, netip.MustParseAddr(addr)); !slices.Equal(got, want) {
+			t.Errorf("lookup(%s) = %v, want %v", addr, got, want)
+		}
+	}
+
+	var walked []string
+	tree.walk(func(p netip.Prefix, _ blockEntry) {
+		walked = append(walked, p.String())
+	})
+	want := []string{"0.0.0.0/0", "10.0.0.0/8", "10.1.2.0/24", "10.1.2.128/25", "10.1.3.0/24", "192.168.0.1/32"}
+	if !slices.Equal(walked, want) {
+		t.Errorf("walk = %v, want %v", walked, want)
+	}
+}
+
+func TestPrefixTreeMerge(t *testing.T) {
+	var tree prefixTree
+	p := mustPrefix(t, "2001:db8::/32")
+	tree.insert(p, blockEntry{categories: 1, listCount: 2, lastSeen: 100})
+	tree.insert(p, blockEntry{categories: 4, listCount: 1, lastSeen: 200})
+	tree.lookup(netip.MustParseAddr("2001:db8::1"), func(_ netip.Prefix, e blockEntry) {
+		if e != (blockEntry{categories: 5, listCount: 2, lastSeen: 200}) {
+			t.Errorf("merged entry = %+v", e)
+		}
+	})
+}
+
+// TestPrefixTreeRandom compares lookups against a linear scan over random
+// prefixes of both families, clustered so that many nest and overlap
+func TestPrefixTreeRandom(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	randomAddr := func(v6 bool) netip.Addr {
+		if v6 {
+			var b [16]byte
+			b[0], b[1], b[15] = 0x20, 0x01, byte(rng.IntN(4))
+			b[2] = byte(rng.IntN(4))
+			return netip.AddrFrom16(b)
+		}
+		return netip.AddrFrom4([4]byte{10, byte(rng.IntN(4)), byte(rng.IntN(4)), byte(rng.IntN(256))})
+	}
+
+	for round := 0; round < 20; round++ {
+		var trees [2]prefixTree
+		var listed []netip.Prefix
+		for i := 0; i < 200; i++ {
+			v6 := rng.IntN(2) == 1
+			addr := randomAddr(v6)
+			p := netip.PrefixFrom(addr, rng.IntN(addr.BitLen()+1)).Masked()
+			trees[boolInt(v6)].insert(p, blockEntry{})
+			if !slices.Contains(listed, p) {
+				listed = append(listed, p)
+			}
+		}
+		if n := trees[0].size + trees[1].size; n != len(listed) {
+			t.Fatalf("size = %d, want %d", n, len(listed))
+		}
+
+		for i := 0; i < 500; i++ {
+			addr := randomAddr(rng.IntN(2) == 1)
+			var want []netip.Prefix
+			for _, p := range listed {
+				if p.Contains(addr) {
+					want = append(want, p)
+				}
+			}
+			slices.SortFunc(want, func(a, b netip.Prefix) int { return a.Bits() - b.Bits() })
+			var got []netip.Prefix
+			trees[boolInt(addr.Is6())].lookup(addr, func(p netip.Prefix, _ blockEntry) {
+				got = append(got, p)
+			})
+			if !slices.Equal(got, want) {
+				t.Fatalf("lookup(%s) = %v, want %v", addr, got, want)
+			}
+		}
+	}
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func TestBitsIPv4Offset(t *testing.T) {
+	a, b := netip.MustParseAddr("192.168.1.1"), netip.MustParseAddr("192.168.129.1")
+	if n := commonBits(a, b); n != 16 {
+		t.Errorf("commonBits = %d, want 16", n)
+	}
+	if n := commonBits(a, a); n != 32 {
+		t.Errorf("commonBits of equal IPv4 addresses = %d, want 32", n)
+	}
+	if bitAt(a, 0) != 1 || bitAt(a, 2) != 0 || bitAt(a, 31) != 1 || bitAt(b, 16) != 1 {
+		t.Error("bitAt doesn't count IPv4 bits from the first octet")
+	}
+	v6 := netip.MustParseAddr("8000::1")
+	if bitAt(v6, 0) != 1 || bitAt(v6, 127) != 1 || bitAt(v6, 1) != 0 {
+		t.Error("bitAt of an IPv6 address")
+	}
+}
+
+func readBlocklist(t *testing.T, files ...string) *Blocklist {
+	t.Helper()
+	b := NewBlocklist()
+	for _, f := range files {
+		if err := b.Read(strings.NewReader(f)); err != nil {
+			t.Fatalf("Read: %v", err)
+		}
+	}
+	return b
+}
+
+func TestBlocklistRead(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		file    string
+		records int
+		skipped int
+		addr    string
+		want    []string // Categories of addr
+	}{
+		{
+			name: "download column order without header",
+			file: "1.2.3.0/24,1,0,0,1,0,0,0,0,0,0,0,3,1700000000\n",
+			addr: "1.2.3.4", want: []string{"bot", "malware"}, records: 1,
+		},
+		{
+			name: "header in another order with aliases and a BOM",
+			file: "\ufeffis-tor,CIDR,is-spider\n1,2001:db8::/32,true\nnot,a,row\n",
+			addr: "2001:db8::1", want: []string{"spider", "tor"}, records: 1, skipped: 1,
+		},
+		{
+			name: "categories column",
+			file: "ip,blocklists\n5.6.7.8,malware spam-bot\n5.6.7.9,unknown\n",
+			addr: "5.6.7.8", want: []string{"malware", "spam-bot"}, records: 1, skipped: 1,
+		},
+		{
+			name: "TXT with comments",
+			file: "# Neutrino IP blocklist\n9.9.9.9\n9.9.9.0/28\n\n",
+			addr: "9.9.9.9", want: []string{}, records: 2,
+		},
+		{
+			name: "IPv4-mapped entries",
+			file: "::ffff:7.7.7.7\n::ffff:8.8.8.0/120\n",
+			addr: "8.8.8.8", want: []string{}, records: 2,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b := readBlocklist(t, tc.file)
+			if b.Len() != tc.records || b.Skipped() != tc.skipped {
+				t.Errorf("records, skipped = %d, %d, want %d, %d", b.Len(), b.Skipped(), tc.records, tc.skipped)
+			}
+			got := b.Lookup(netip.MustParseAddr(tc.addr), true)
+			if !got.Is_listed || !slices.Equal(got.Blocklists, tc.want) {
+				t.Errorf("Lookup(%s) = %v %v, want listed in %v", tc.addr, got.Is_listed, got.Blocklists, tc.want)
+			}
+		})
+	}
+
+	if err := NewBlocklist().Read(strings.NewReader("is-bot,is-tor\n1,0\n")); err == nil {
+		t.Error("header without an address column read, want an error")
+	}
+}
+
+func TestBlocklistLookup(t *testing.T) {
+	b := readBlocklist(t,
+		"ip,is-bot,is-malware,is-vpn,list-count,last-seen\n"+
+			"10.0.0.0/8,1,0,0,1,100\n"+
+			"10.1.0.0/16,0,1,1,2,300\n"+
+			"10.2.0.0/16,0,0,1,1,200\n"+
+			"0.0.0.0/0,0,0,0,0,0\n",
+		"ip,is-tor\n2001:db8::/32,1\n2001:db8::1,1\n",
+	)
+
+	for _, tc := range []struct {
+		addr   string
+		vpn    bool
+		listed bool
+		cidr   string
+		lists  []string
+		count  int
+	}{
+		{"10.1.2.3", false, true, "10.1.0.0/16", []string{"bot", "malware"}, 2},
+		{"10.1.2.3", true, true, "10.1.0.0/16", []string{"bot", "malware", "vpn"}, 3},
+		// Only listed as a VPN provider inside the /8, which is reported instead
+		{"10.2.2.3", false, true, "10.0.0.0/8", []string{"bot"}, 1},
+		{"10.2.2.3", true, true, "10.2.0.0/16", []string{"bot", "vpn"}, 2},
+		{"::ffff:10.1.2.3", false, true, "10.1.0.0/16", []string{"bot", "malware"}, 2},
+		// Only the /0 without categories
+		{"192.0.2.1", false, true, "0.0.0.0/0", []string{}, 1},
+		{"2001:db8::1", false, true, "2001:db8::1/128", []string{"tor"}, 1},
+		{"2001:db8:1::", false, true, "2001:db8::/32", []string{"tor"}, 1},
+		{"2001:db9::", false, false, "", []string{}, 0},
+	} {
+		got := b.Lookup(netip.MustParseAddr(tc.addr), tc.vpn)
+		if got.Is_listed != tc.listed || got.Cidr != tc.cidr || !slices.Equal(got.Blocklists, tc.lists) || got.List_count != tc.count {
+			t.Errorf("Lookup(%s, vpn %v) = listed %v, cidr %q, %v, count %d; want %v, %q, %v, %d",
+				tc.addr, tc.vpn, got.Is_listed, got.Cidr, got.Blocklists, got.List_count, tc.listed, tc.cidr, tc.lists, tc.count)
+		}
+		if got.Is_vpn != slices.Contains(tc.lists, "vpn") || got.Is_malware != slices.Contains(tc.lists, "malware") {
+			t.Errorf("Lookup(%s, vpn %v) flags don't match %v", tc.addr, tc.vpn, got.Blocklists)
+		}
+	}
+	if got := b.Lookup(netip.MustParseAddr("10.1.0.0"), false); got.Last_seen != 300 {
+		t.Errorf("last-seen = %d, want the latest, 300", got.Last_seen)
+	}
+
+	vpnOnly := readBlocklist(t, "ip,is-vpn\n172.16.0.0/12,1\n")
+	if vpnOnly.Lookup(netip.MustParseAddr("172.16.0.1"), false).Is_listed {
+		t.Error("VPN-only entry listed without vpn")
+	}
+	if !vpnOnly.Lookup(netip.MustParseAddr("172.16.0.1"), true).Is_listed {
+		t.Error("VPN-only entry not listed with vpn")
+	}
+}
+
+func TestParseIP(t *testing.T) {
+	for in, want := range map[string]string{
+		"1.2.3.4":               "1.2.3.4",
+		" 1.2.3.4 ":             "1.2.3.4",
+		"1.2.3.4:8080":          "1.2.3.4",
+		"1.2.3.0/24":            "1.2.3.0",
+		"1.2.3.4, 5.6.7.8":      "1.2.3.4",
+		"::ffff:1.2.3.4":        "1.2.3.4",
+		"[2001:db8::1]:443":     "2001:db8::1",
+		"2001:DB8:0:0::1":       "2001:db8::1",
+		"2001:db8::/32,1.1.1.1": "2001:db8::",
+	} {
+		got, err := ParseIP(in)
+		if err != nil || got.String() != want {
+			t.Errorf("ParseIP(%q) = %v, %v, want %s", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "example.com", "1.2.3", "1.2.3.4/33"} {
+		if _, err := ParseIP(in); err == nil {
+			t.Errorf("ParseIP(%q) succeeded, want an error", in)
+		}
+	}
+}
@@ -0,0 +1,333 @@
+// Package dataset downloads bulk data feeds from the API and indexes them in
+// memory, so the local_ tools can answer lookups without calling the API
+package dataset
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/neutrino-api/mcp-server/client"
+	"github.com/neutrino-api/mcp-server/config"
+)
+
+// Index is the parsed, searchable form of a dataset
+type Index interface {
+	Len() int     // Number of records
+	Skipped() int // Rows that couldn't be parsed
+}
+
+// File is one download making up a dataset
+type File struct {
+	Name string         // e.g. "bin-list.csv"
+	Args map[string]any // Arguments of the download endpoint
+}
+
+// Loader is the part of a Dataset that doesn't depend on its index type
+type Loader interface {
+	Name() string
+	// Run keeps the dataset up to date until ctx is done
+	Run(ctx context.Context, cfg *config.APIConfig, datasetCfg *config.DatasetConfig)
+	// Status reports the version in use and the last refresh
+	Status() Status
+}
+
+// Status reports the state of a dataset. Times are RFC 3339, empty if the
+// event hasn't happened yet.
+type Status struct {
+	Name        string   `json:"name"`
+	Loaded      bool     `json:"loaded"`                 // Whether a version is in use
+	Version     string   `json:"version,omitempty"`      // The version in use, empty if it wasn't stored
+	Downloaded  string   `json:"downloaded,omitempty"`   // When the version in use was downloaded
+	AgeSeconds  int64    `json:"age-seconds"`            // Seconds since the version in use was downloaded
+	Records     int      `json:"records"`                // Records of the version in use
+	Skipped     int      `json:"skipped-rows"`           // Rows of the version in use that couldn't be parsed
+	LastRefresh string   `json:"last-refresh,omitempty"` // When the last refresh finished, successful or not
+	LastError   string   `json:"last-error,omitempty"`   // Why the last refresh failed, empty if it succeeded
+	NextRefresh string   `json:"next-refresh,omitempty"` // When the next refresh is due
+	Versions    []string `json:"versions,omitempty"`     // Versions on disk, newest first
+}
+
+// Dataset is a data feed downloaded from one endpoint, as one or more files
+type Dataset[T Index] struct {
+	name     string
+	endpoint client.Endpoint
+	files    []File
+	parse    func(files map[string][]byte) (T, error)
+	// options returns configured arguments added to those of every file
+	options func(cfg *config.DatasetConfig) map[string]any
+
+	current atomic.Pointer[version[T]] // nil until a version is loaded
+
+	mu          sync.Mutex // Guards the fields below, reported by Status
+	dir         string
+	lastRefresh time.Time
+	lastError   error
+	nextRefresh time.Time
+}
+
+// version is a loaded version of a dataset
+type version[T Index] struct {
+	name       string // Empty for versions that weren't stored
+	downloaded time.Time
+	index      T
+}
+
+// all holds every dataset in the order they are declared
+var all []Loader
+
+func register[T Index](d *Dataset[T]) *Dataset[T] {
+	all = append(all, d)
+	return d
+}
+
+// Lookup returns the dataset with the given name
+func Lookup(name string) (Loader, bool) {
+	for _, d := range all {
+		if d.Name() == name {
+			return d, true
+		}
+	}
+	return nil, false
+}
+
+// Names returns the names of all datasets
+func Names() []string {
+	names := make([]string, len(all))
+	for i, d := range all {
+		names[i] = d.Name()
+	}
+	return names
+}
+
+// Report is the status of the datasets being kept up to date
+type Report struct {
+	Datasets []Status `json:"datasets"`
+}
+
+// StatusReport returns the status of every dataset Run was called for
+func StatusReport() Report {
+	report := Report{Datasets: []Status{}}
+	for _, d := range all {
+		// Only running datasets have a next refresh
+		if status := d.Status(); status.NextRefresh != "" {
+			report.Datasets = append(report.Datasets, status)
+		}
+	}
+	return report
+}
+
+func (d *Dataset[T]) Name() string {
+	return d.name
+}
+
+// Get returns the index in use, or a tool error while there is none
+func (d *Dataset[T]) Get() (T, error) {
+	index, _, err := d.Current()
+	return index, err
+}
+
+// Current is Get that also returns the version of the index
+func (d *Dataset[T]) Current() (T, string, error) {
+	v := d.current.Load()
+	if v == nil {
+		var zero T
+		return zero, "", &client.ToolError{
+			Code:      client.CodeUpstreamUnavailable,
+			Message:   fmt.Sprintf("The %s dataset has not been downloaded yet", d.name),
+			Retryable: true,
+		}
+	}
+	return v.index, v.name, nil
+}
+
+// Run loads the newest valid version from the dataset directory, then
+// downloads the dataset whenever the version in use is older than the
+// refresh interval. Failed downloads are retried after a minute, doubling
+// the delay up to the refresh interval.
+func (d *Dataset[T]) Run(ctx context.Context, cfg *config.APIConfig, datasetCfg *config.DatasetConfig) {
+	next := time.Now()
+	d.mu.Lock()
+	d.dir, d.nextRefresh = datasetCfg.Dir, next
+	d.mu.Unlock()
+
+	if datasetCfg.Dir != "" {
+		if err := d.loadStored(datasetCfg); err != nil {
+			log.Printf("No stored version of dataset %s loaded: %v", d.name, err)
+		}
+		if v := d.current.Load(); v != nil {
+			next = v.downloaded.Add(datasetCfg.RefreshInterval)
+		}
+	}
+
+	retry := time.Minute
+	for {
+		d.mu.Lock()
+		d.nextRefresh = next
+		d.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(next)):
+		}
+
+		err := d.refresh(ctx, cfg, datasetCfg)
+		now := time.Now()
+		d.mu.Lock()
+		d.lastRefresh, d.lastError = now, err
+		d.mu.Unlock()
+		if err != nil {
+			log.Printf("Failed to refresh dataset %s, retrying in %v: %v", d.name, retry, err)
+			next = now.Add(retry)
+			retry = min(retry*2, datasetCfg.RefreshInterval)
+			continue
+		}
+		next = now.Add(datasetCfg.RefreshInterval)
+		retry = time.Minute
+	}
+}
+
+// loadStored loads the newest stored version that passes validation. Older
+// versions are only tried when newer ones fail, so removing the newest
+// version directory rolls the dataset back on the next start.
+func (d *Dataset[T]) loadStored(datasetCfg *config.DatasetConfig) error {
+	versions, err := listVersions(datasetCfg.Dir, d.name)
+	if err != nil {
+		return err
+	}
+	if len(versions) == 0 {
+		return fmt.Errorf("none in %s", datasetCfg.Dir)
+	}
+	for _, name := range versions {
+		index, err := d.ReadVersion(datasetCfg.Dir, name)
+		if err == nil {
+			err = d.validate(index, datasetCfg)
+		}
+		if err != nil {
+			log.Printf("Skipping version %s of dataset %s: %v", name, d.name, err)
+			continue
+		}
+		d.current.Store(&version[T]{name: name, downloaded: versionTime(name), index: index})
+		log.Printf("Loaded version %s of dataset %s with %d records", name, d.name, index.Len())
+		return nil
+	}
+	return fmt.Errorf("all %d versions failed validation", len(versions))
+}
+
+// refresh downloads, parses and validates the dataset, then swaps it in for
+// the version in use. Lookups in progress finish on the old version.
+func (d *Dataset[T]) refresh(ctx context.Context, cfg *config.APIConfig, datasetCfg *config.DatasetConfig) error {
+	start := time.Now()
+	files := make(map[string][]byte, len(d.files))
+	for _, f := range d.files {
+		args := maps.Clone(f.Args)
+		if d.options != nil {
+			maps.Copy(args, d.options(datasetCfg))
+		}
+		resp, err := client.Default().Do(ctx, cfg, d.endpoint, args)
+		if err != nil {
+			return fmt.Errorf("failed to download %s: %w", f.Name, err)
+		}
+		files[f.Name] = resp.Body
+	}
+	index, err := d.parse(files)
+	if err != nil {
+		return err
+	}
+	if err := d.validate(index, datasetCfg); err != nil {
+		return err
+	}
+	if current := d.current.Load(); current != nil {
+		// A sudden drop suggests a truncated or broken download
+		least := int(float64(current.index.Len()) * datasetCfg.MinRecordRatio)
+		if index.Len() < least {
+			return fmt.Errorf("download has %d records, fewer than %d of the %d in use", index.Len(), least, current.index.Len())
+		}
+	}
+
+	v := &version[T]{downloaded: start, index: index}
+	if datasetCfg.Dir != "" {
+		// A version that can't be stored is still used, it is only lost on restart
+		if v.name, err = saveVersion(datasetCfg.Dir, d.name, start, files); err != nil {
+			log.Printf("Failed to store dataset %s: %v", d.name, err)
+		}
+	}
+	d.current.Store(v)
+	log.Printf("Downloaded dataset %s with %d records in %v", d.name, index.Len(), time.Since(start).Round(time.Millisecond))
+
+	if datasetCfg.Dir != "" {
+		if err := pruneVersions(datasetCfg.Dir, d.name, datasetCfg.KeepVersions, v.name); err != nil {
+			log.Printf("Failed to delete old versions of dataset %s: %v", d.name, err)
+		}
+	}
+	return nil
+}
+
+// validate rejects empty datasets and those with too many unparsable rows
+func (d *Dataset[T]) validate(index T, datasetCfg *config.DatasetConfig) error {
+	if index.Len() == 0 {
+		return fmt.Errorf("%s has no records", d.name)
+	}
+	rows := index.Len() + index.Skipped()
+	if float64(index.Skipped()) > float64(rows)*datasetCfg.MaxSkippedRatio {
+		return fmt.Errorf("%d of %d rows of %s couldn't be parsed", index.Skipped(), rows, d.name)
+	}
+	return nil
+}
+
+// Dir returns the dataset directory Run was called with, empty if none
+func (d *Dataset[T]) Dir() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.dir
+}
+
+// Versions returns the versions stored in dir, newest first
+func (d *Dataset[T]) Versions(dir string) ([]string, error) {
+	return listVersions(dir, d.name)
+}
+
+// ReadVersion parses a version stored in dir, without using it
+func (d *Dataset[T]) ReadVersion(dir, name string) (T, error) {
+	names := make([]string, len(d.files))
+	for i, f := range d.files {
+		names[i] = f.Name
+	}
+	files, err := readVersion(dir, d.name, name, names)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return d.parse(files)
+}
+
+func (d *Dataset[T]) Status() Status {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	s := Status{Name: d.name}
+	if v := d.current.Load(); v != nil {
+		s.Loaded = true
+		s.Version = v.name
+		s.Downloaded = v.downloaded.UTC().Format(time.RFC3339)
+		s.AgeSeconds = int64(time.Since(v.downloaded).Seconds())
+		s.Records = v.index.Len()
+		s.Skipped = v.index.Skipped()
+	}
+	if !d.lastRefresh.IsZero() {
+		s.LastRefresh = d.lastRefresh.UTC().Format(time.RFC3339)
+	}
+	if d.lastError != nil {
+		s.LastError = d.lastError.Error()
+	}
+	if !d.nextRefresh.IsZero() {
+		s.NextRefresh = d.nextRefresh.UTC().Format(time.RFC3339)
+	}
+	if d.dir != "" {
+		s.Versions, _ = listVersions(d.dir, d.name)
+	}
+	return s
+}
@@ -0,0 +1,143 @@
+package dataset
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/netip"
+	"slices"
+)
+
+// AnyCategory names the diff of all listed addresses, whatever their
+// categories, including entries of TXT downloads that have none. Addresses
+// only listed as VPN providers count when the diff includes VPN providers.
+const AnyCategory = "any"
+
+// BlocklistDiff is what changed between two versions of the IP blocklist
+type BlocklistDiff struct {
+	From       string         `json:"from"` // The older version
+	To         string         `json:"to"`   // The newer version
+	Categories []CategoryDiff `json:"categories"`
+}
+
+// CategoryDiff lists the addresses added to and removed from one category
+// as the fewest CIDRs covering them, IPv4 first
+type CategoryDiff struct {
+	Category  string   `json:"category"`
+	Added     []string `json:"added"`
+	Removed   []string `json:"removed"`
+	Truncated bool     `json:"truncated,omitempty"` // Whether Added or Removed were cut off at the limit
+}
+
+// DiffStoredBlocklists compares two versions of the ip-blocklist dataset
+// stored in dir, see DiffBlocklists. An empty to is the newest version and
+// an empty from the version before to.
+func DiffStoredBlocklists(dir, from, to string, categories Categories, vpn bool, limit int) (*BlocklistDiff, error) {
+	versions, err := IPBlocklist.Versions(dir)
+	if err != nil {
+		return nil, err
+	}
+	if to == "" {
+		if len(versions) == 0 {
+			return nil, fmt.Errorf("no versions of %s stored in %s", IPBlocklist.Name(), dir)
+		}
+		to = versions[0]
+	}
+	i := slices.Index(versions, to)
+	if i < 0 {
+		return nil, fmt.Errorf("version %s of %s not found in %s", to, IPBlocklist.Name(), dir)
+	}
+	if from == "" {
+		if i+1 == len(versions) {
+			return nil, fmt.Errorf("no version of %s older than %s stored in %s", IPBlocklist.Name(), to, dir)
+		}
+		from = versions[i+1]
+	} else if !slices.Contains(versions, from) {
+		return nil, fmt.Errorf("version %s of %s not found in %s", from, IPBlocklist.Name(), dir)
+	}
+
+	older, err := IPBlocklist.ReadVersion(dir, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read version %s: %w", from, err)
+	}
+	newer, err := IPBlocklist.ReadVersion(dir, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read version %s: %w", to, err)
+	}
+	return &BlocklistDiff{From: from, To: to, Categories: DiffBlocklists(older, newer, categories, vpn, limit)}, nil
+}
+
+// DiffBlocklists compares the addresses listed in each category, and in
+// any category, by the two blocklists. Only the given categories are
+// compared, all when categories is 0. As in ExportBlocklist, addresses only
+// listed as VPN providers are left out, along with the vpn category, unless
+// vpn is set or the vpn category is selected. A positive limit caps the
+// CIDRs reported per list.
+func DiffBlocklists(from, to *Blocklist, categories Categories, vpn bool, limit int) []CategoryDiff {
+	vpn = vpn || categories&vpnCategory != 0
+	if categories == 0 {
+		categories = ^Categories(0)
+	}
+	if !vpn {
+		categories &^= vpnCategory
+	}
+	oldSets, newSets := categorySets(from, vpn), categorySets(to, vpn)
+	diffs := []CategoryDiff{}
+	for _, name := range append([]string{AnyCategory}, BlocklistCategories...) {
+		if name != AnyCategory && !categories.Has(name) {
+			continue
+		}
+		d := CategoryDiff{
+			Category: name,
+			Added:    cidrStrings(newSets[name].subtract(oldSets[name]).prefixes()),
+			Removed:  cidrStrings(oldSets[name].subtract(newSets[name]).prefixes()),
+		}
+		if limit > 0 && (len(d.Added) > limit || len(d.Removed) > limit) {
+			d.Added, d.Removed = d.Added[:min(len(d.Added), limit)], d.Removed[:min(len(d.Removed), limit)]
+			d.Truncated = true
+		}
+		diffs = append(diffs, d)
+	}
+	return diffs
+}
+
+// WriteCSV writes the diff as category,change,cidr rows
+func (d *BlocklistDiff) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"category", "change", "cidr"})
+	for _, c := range d.Categories {
+		for _, cidr := range c.Added {
+			cw.Write([]string{c.Category, "added", cidr})
+		}
+		for _, cidr := range c.Removed {
+			cw.Write([]string{c.Category, "removed", cidr})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// categorySets returns the addresses listed by b in each category and in
+// any category, see walkVisible for vpn
+func categorySets(b *Blocklist, vpn bool) map[string]rangeSet {
+	prefixes := map[string][]netip.Prefix{}
+	b.walkVisible(vpn, func(p netip.Prefix, categories Categories) {
+		prefixes[AnyCategory] = append(prefixes[AnyCategory], p)
+		for _, name := range categories.Names() {
+			prefixes[name] = append(prefixes[name], p)
+		}
+	})
+	sets := make(map[string]rangeSet, len(prefixes))
+	for name, list := range prefixes {
+		sets[name] = newRangeSet(list)
+	}
+	return sets
+}
+
+func cidrStrings(prefixes []netip.Prefix) []string {
+	out := make([]string, len(prefixes))
+	for i, p := range prefixes {
+		out[i] = p.String()
+	}
+	return out
+}
@@ -0,0 +1,87 @@
+package dataset
+
+import (
+	"strings"
+	"testing"
+)
+
+// diffOf returns the diff of one category as "added | removed"
+func diffOf(diffs []CategoryDiff, category string) string {
+	for _, d := range diffs {
+		if d.Category == category {
+			return strings.Join(d.Added, " ") + " | " + strings.Join(d.Removed, " ")
+		}
+	}
+	return "not compared"
+}
+
+func TestDiffBlocklists(t *testing.T) {
+	older := readBlocklist(t, "ip,is-bot,is-malware,is-vpn\n"+
+		"10.0.0.0/24,1,0,0\n"+
+		"10.0.1.0/24,0,1,0\n"+
+		"10.0.2.0/24,0,0,1\n"+
+		"2001:db8::/32,1,0,0\n")
+	newer := readBlocklist(t, "ip,is-bot,is-malware,is-vpn\n"+
+		// Split into two /25s, which isn't a change
+		"10.0.0.0/25,1,0,0\n"+
+		"10.0.0.128/25,1,0,0\n"+
+		// Moved from malware to bot
+		"10.0.1.0/24,1,0,0\n"+
+		// VPN provider range shrunk and a new one listed
+		"10.0.2.0/25,0,0,1\n"+
+		"10.0.3.0/24,0,0,1\n"+
+		"2001:db8::/33,1,0,0\n"+
+		"192.0.2.1,0,1,1\n")
+
+	diffs := DiffBlocklists(older, newer, 0, false, 0)
+	for category, want := range map[string]string{
+		AnyCategory: "192.0.2.1/32 | 2001:db8:8000::/33",
+		"bot":       "10.0.1.0/24 | 2001:db8:8000::/33",
+		"malware":   "192.0.2.1/32 | 10.0.1.0/24",
+		"spam-bot":  " | ",
+		"vpn":       "not compared",
+	} {
+		if got := diffOf(diffs, category); got != want {
+			t.Errorf("%s: %q, want %q", category, got, want)
+		}
+	}
+
+	// With VPN providers, as when the vpn category is selected
+	for _, diffs := range [][]CategoryDiff{
+		DiffBlocklists(older, newer, 0, true, 0),
+		DiffBlocklists(older, newer, vpnCategory, false, 0),
+	} {
+		for category, want := range map[string]string{
+			AnyCategory: "10.0.3.0/24 192.0.2.1/32 | 10.0.2.128/25 2001:db8:8000::/33",
+			"vpn":       "10.0.3.0/24 192.0.2.1/32 | 10.0.2.128/25",
+		} {
+			if got := diffOf(diffs, category); got != want {
+				t.Errorf("with vpn, %s: %q, want %q", category, got, want)
+			}
+		}
+	}
+
+	bot, _ := ParseCategories([]string{"bot"})
+	diffs = DiffBlocklists(older, newer, bot, false, 0)
+	if len(diffs) != 2 || diffs[0].Category != AnyCategory || diffs[1].Category != "bot" {
+		t.Errorf("categories compared = %+v, want any and bot", diffs)
+	}
+
+	// Two CIDRs were added to and removed from any category
+	if d := DiffBlocklists(older, newer, 0, true, 1)[0]; !d.Truncated || len(d.Added) != 1 || len(d.Removed) != 1 {
+		t.Errorf("limited diff = %+v, want one CIDR each and truncated", d)
+	}
+	if d := DiffBlocklists(older, newer, 0, true, 2)[0]; d.Truncated {
+		t.Errorf("diff within the limit marked truncated: %+v", d)
+	}
+}
+
+func TestDiffBlocklistsUnchanged(t *testing.T) {
+	a := readBlocklist(t, "10.0.0.0/23\n10.0.2.0/24\n")
+	b := readBlocklist(t, "10.0.0.0/24\n10.0.1.0/24\n10.0.2.0/25\n10.0.2.128/25\n")
+	for _, d := range DiffBlocklists(a, b, 0, false, 0) {
+		if len(d.Added)+len(d.Removed) > 0 {
+			t.Errorf("%s: %+v, want no change", d.Category, d)
+		}
+	}
+}
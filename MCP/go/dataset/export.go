@@ -0,0 +1,234 @@
+package dataset
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/netip"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ExportFormats are the firewall formats the IP blocklist can be exported as
+var ExportFormats = []string{"nftables", "ipset", "iptables", "cidr"}
+
+// DefaultExportName names the exported set or chain unless another is given
+const DefaultExportName = "neutrino-blocklist"
+
+// exportExtensions are the usual file extensions of the ExportFormats
+var exportExtensions = map[string]string{
+	"nftables": ".nft",
+	"ipset":    ".ipset",
+	"iptables": ".rules",
+	"cidr":     ".txt",
+}
+
+// exportName is a name accepted by nft, ipset and iptables alike, short
+// enough for a family suffix within the 28 characters of an iptables chain
+var exportName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]{0,23}$`)
+
+// ExportOptions selects what ExportBlocklist writes
+type ExportOptions struct {
+	Format     string     // One of ExportFormats
+	Family     string     // "ipv4" or "ipv6", both when empty
+	Categories Categories // Only addresses listed in these categories, all when 0
+	VPN        bool       // Include addresses only listed as VPN providers
+	Name       string     // The set or chain name, DefaultExportName when empty
+	Table      string     // The nftables table holding the sets, "filter" when empty
+	Version    string     // The dataset version, noted in the header
+}
+
+// validate checks the options and fills in the defaults
+func (o *ExportOptions) validate() error {
+	if !slices.Contains(ExportFormats, o.Format) {
+		return fmt.Errorf("unknown export format %q, expected one of %s", o.Format, strings.Join(ExportFormats, ", "))
+	}
+	switch o.Family {
+	case "", "ipv4", "ipv6":
+	default:
+		return fmt.Errorf("unknown address family %q, expected ipv4 or ipv6", o.Family)
+	}
+	if o.Format == "iptables" && o.Family == "" {
+		return fmt.Errorf("iptables rules are per address family, choose ipv4 (iptables-restore) or ipv6 (ip6tables-restore)")
+	}
+	if o.Name == "" {
+		o.Name = DefaultExportName
+	}
+	if o.Table == "" {
+		o.Table = "filter"
+	}
+	for _, name := range []string{o.Name, o.Table} {
+		if !exportName.MatchString(name) {
+			return fmt.Errorf("invalid name %q: use up to 24 letters, digits, '-' and '_', starting with a letter", name)
+		}
+	}
+	return nil
+}
+
+// ExportExtension returns the usual file extension of an export format
+func ExportExtension(format string) string {
+	return exportExtensions[format]
+}
+
+// ExportBlocklist writes the addresses b lists as firewall configuration,
+// aggregated into the fewest CIDRs covering them, and returns the number of
+// CIDRs written. Addresses only listed as VPN providers are left out unless
+// VPN is set or the vpn category is selected, as by Lookup.
+func ExportBlocklist(w io.Writer, b *Blocklist, opts ExportOptions) (int, error) {
+	if err := opts.validate(); err != nil {
+		return 0, err
+	}
+	vpn := opts.VPN || opts.Categories&vpnCategory != 0
+	var v4, v6 []netip.Prefix
+	b.walkVisible(vpn, func(p netip.Prefix, categories Categories) {
+		if opts.Categories != 0 && categories&opts.Categories == 0 {
+			return
+		}
+		if p.Addr().Is4() {
+			v4 = append(v4, p)
+		} else {
+			v6 = append(v6, p)
+		}
+	})
+
+	// Exporting both families takes a set per family, told apart by a suffix
+	setName := func(suffix string) string {
+		if opts.Family == "" {
+			return opts.Name + "-" + suffix
+		}
+		return opts.Name
+	}
+	var families []exportFamily
+	if opts.Family != "ipv6" {
+		families = append(families, exportFamily{"ipv4", setName("v4"), newRangeSet(v4).prefixes()})
+	}
+	if opts.Family != "ipv4" {
+		families = append(families, exportFamily{"ipv6", setName("v6"), newRangeSet(v6).prefixes()})
+	}
+
+	bw := bufio.NewWriter(w)
+	total := 0
+	for _, f := range families {
+		total += len(f.cidrs)
+	}
+	if opts.Format != "cidr" {
+		writeExportHeader(bw, opts, families, total)
+	}
+	switch opts.Format {
+	case "nftables":
+		writeNftables(bw, opts.Table, families)
+	case "ipset":
+		writeIpset(bw, families)
+	case "iptables":
+		writeIptables(bw, families[0])
+	case "cidr":
+		for _, f := range families {
+			for _, p := range f.cidrs {
+				fmt.Fprintln(bw, p)
+			}
+		}
+	}
+	return total, bw.Flush()
+}
+
+// exportFamily is the set or chain exported for one address family
+type exportFamily struct {
+	family string
+	name   string
+	cidrs  []netip.Prefix
+}
+
+func writeExportHeader(w io.Writer, opts ExportOptions, families []exportFamily, total int) {
+	categories := "all"
+	if opts.Categories != 0 {
+		categories = strings.Join(opts.Categories.Names(), ", ")
+	}
+	fmt.Fprint(w, "# Neutrino IP blocklist")
+	if opts.Version != "" {
+		fmt.Fprintf(w, " version %s", opts.Version)
+	}
+	fmt.Fprintf(w, ", %d CIDRs, categories: %s\n", total, categories)
+	switch opts.Format {
+	case "nftables":
+		fmt.Fprintln(w, "# Load with: nft -f FILE")
+		for _, f := range families {
+			fmt.Fprintf(w, "# Match with: %s saddr @%s drop\n", nftFamily(f.family), f.name)
+		}
+	case "ipset":
+		fmt.Fprintln(w, "# Load with: ipset restore < FILE")
+		for _, f := range families {
+			fmt.Fprintf(w, "# Match with: %s -I INPUT -m set --match-set %s src -j DROP\n", iptablesCommand(f.family), f.name)
+		}
+	case "iptables":
+		f := families[0]
+		fmt.Fprintf(w, "# Load with: %s-restore --noflush FILE\n", iptablesCommand(f.family))
+		fmt.Fprintf(w, "# Match with: %s -I INPUT -j %s\n", iptablesCommand(f.family), f.name)
+	}
+}
+
+// writeNftables declares an interval set per family, which leaves existing
+// sets in place, then replaces their elements
+func writeNftables(w io.Writer, table string, families []exportFamily) {
+	fmt.Fprintf(w, "table inet %s {\n", table)
+	for _, f := range families {
+		fmt.Fprintf(w, "\tset %s {\n\t\ttype %s_addr\n\t\tflags interval\n\t}\n", f.name, f.family)
+	}
+	fmt.Fprintln(w, "}")
+	for _, f := range families {
+		fmt.Fprintf(w, "flush set inet %s %s\n", table, f.name)
+		if len(f.cidrs) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "add element inet %s %s {\n", table, f.name)
+		for i, p := range f.cidrs {
+			sep := ","
+			if i == len(f.cidrs)-1 {
+				sep = ""
+			}
+			fmt.Fprintf(w, "\t%s%s\n", p, sep)
+		}
+		fmt.Fprintln(w, "}")
+	}
+}
+
+// writeIpset creates a hash:net set per family, unless it exists, then
+// replaces its members
+func writeIpset(w io.Writer, families []exportFamily) {
+	for _, f := range families {
+		family := "inet"
+		if f.family == "ipv6" {
+			family = "inet6"
+		}
+		fmt.Fprintf(w, "create %s hash:net family %s maxelem %d -exist\n", f.name, family, max(65536, len(f.cidrs)))
+		fmt.Fprintf(w, "flush %s\n", f.name)
+		for _, p := range f.cidrs {
+			fmt.Fprintf(w, "add %s %s\n", f.name, p)
+		}
+	}
+}
+
+// writeIptables writes a chain dropping the listed sources, for
+// iptables-restore --noflush, which flushes the chain if it exists
+func writeIptables(w io.Writer, f exportFamily) {
+	fmt.Fprintf(w, "*filter\n:%s - [0:0]\n", f.name)
+	for _, p := range f.cidrs {
+		fmt.Fprintf(w, "-A %s -s %s -j DROP\n", f.name, p)
+	}
+	fmt.Fprintln(w, "COMMIT")
+}
+
+// nftFamily returns the nft address family keyword, ip or ip6
+func nftFamily(family string) string {
+	if family == "ipv6" {
+		return "ip6"
+	}
+	return "ip"
+}
+
+func iptablesCommand(family string) string {
+	if family == "ipv6" {
+		return "ip6tables"
+	}
+	return "iptables"
+}
@@ -0,0 +1,115 @@
+package dataset
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+const exportBlocklist = "ip,is-bot,is-malware,is-vpn\n" +
+	// Merged into 10.0.0.0/24
+	"10.0.0.0/25,1,0,0\n" +
+	"10.0.0.128/25,1,0,0\n" +
+	"192.0.2.1,0,1,0\n" +
+	// Only a VPN provider
+	"198.51.100.0/24,0,0,1\n" +
+	// A VPN provider listed as a bot too
+	"203.0.113.7,1,0,1\n" +
+	// Merged into 2001:db8::/32
+	"2001:db8::/33,1,0,0\n" +
+	"2001:db8:8000::/33,1,0,0\n"
+
+func categories(t *testing.T, names ...string) Categories {
+	t.Helper()
+	c, err := ParseCategories(names)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// TestExportBlocklist compares each export with testdata/export/NAME.golden,
+// go test -run TestExportBlocklist -update rewrites them
+func TestExportBlocklist(t *testing.T) {
+	b := readBlocklist(t, exportBlocklist)
+	for _, tc := range []struct {
+		name  string
+		opts  ExportOptions
+		cidrs int
+	}{
+		{"nftables", ExportOptions{Format: "nftables", Version: "20261018T000000Z"}, 4},
+		{"nftables-ipv4", ExportOptions{Format: "nftables", Family: "ipv4", Name: "blocked", Table: "fw"}, 3},
+		// No IPv6 malware: the set is still declared and emptied
+		{"nftables-malware", ExportOptions{Format: "nftables", Categories: categories(t, "malware")}, 1},
+		{"ipset", ExportOptions{Format: "ipset"}, 4},
+		{"ipset-vpn", ExportOptions{Format: "ipset", Family: "ipv4", Categories: categories(t, "vpn")}, 2},
+		{"iptables-ipv4", ExportOptions{Format: "iptables", Family: "ipv4", Categories: categories(t, "bot")}, 2},
+		{"iptables-ipv6", ExportOptions{Format: "iptables", Family: "ipv6", Name: "NEUTRINO_V6"}, 1},
+		{"cidr", ExportOptions{Format: "cidr"}, 4},
+		{"cidr-vpn", ExportOptions{Format: "cidr", VPN: true}, 5},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			n, err := ExportBlocklist(&buf, b, tc.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != tc.cidrs {
+				t.Errorf("%d CIDRs, want %d", n, tc.cidrs)
+			}
+
+			golden := filepath.Join("testdata", "export", tc.name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, buf.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != string(want) {
+				t.Errorf("output differs from %s:\n%s", golden, buf.String())
+			}
+		})
+	}
+}
+
+func TestExportBlocklistRejects(t *testing.T) {
+	b := readBlocklist(t, exportBlocklist)
+	for _, tc := range []struct {
+		opts ExportOptions
+		want string // Part of the error
+	}{
+		{ExportOptions{Format: "pf"}, "unknown export format"},
+		{ExportOptions{Format: "nftables", Family: "inet"}, "unknown address family"},
+		{ExportOptions{Format: "iptables"}, "per address family"},
+		{ExportOptions{Format: "ipset", Name: "1blocklist"}, "invalid name"},
+		{ExportOptions{Format: "ipset", Name: "block list"}, "invalid name"},
+		{ExportOptions{Format: "ipset", Name: "x\nflush"}, "invalid name"},
+		{ExportOptions{Format: "nftables", Name: "x; flush ruleset"}, "invalid name"},
+		{ExportOptions{Format: "nftables", Table: "filter}"}, "invalid name"},
+		// With a -v4 suffix this would exceed the 28 characters of a chain
+		{ExportOptions{Format: "iptables", Family: "ipv4", Name: strings.Repeat("a", 25)}, "invalid name"},
+	} {
+		var buf bytes.Buffer
+		_, err := ExportBlocklist(&buf, b, tc.opts)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%+v: err = %v, want %q", tc.opts, err, tc.want)
+		}
+		if buf.Len() > 0 {
+			t.Errorf("%+v: wrote %q despite the error", tc.opts, buf.String())
+		}
+	}
+
+	// The longest name allowed
+	if _, err := ExportBlocklist(&bytes.Buffer{}, b, ExportOptions{Format: "iptables", Family: "ipv4", Name: "a" + strings.Repeat("_", 23)}); err != nil {
+		t.Error(err)
+	}
+}
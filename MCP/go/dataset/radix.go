@@ -0,0 +1,117 @@
+package dataset
+
+import "net/netip"
+
+// prefixTree is a path-compressed binary radix tree of the IP prefixes of
+// one address family. Every node's prefix contains those of its children,
+// so the listings covering an address lie on a single path from the root.
+type prefixTree struct {
+	root *prefixNode
+	size int // Listed prefixes, not counting branch nodes
+}
+
+type prefixNode struct {
+	prefix netip.Prefix
+	child  [2]*prefixNode
+	listed bool // false for nodes that only join two subtrees
+	entry  blockEntry
+}
+
+// insert adds a listing, merging it into an existing one for the same prefix
+func (t *prefixTree) insert(p netip.Prefix, e blockEntry) {
+	link := &t.root
+	for {
+		n := *link
+		if n == nil {
+			*link = &prefixNode{prefix: p, listed: true, entry: e}
+			t.size++
+			return
+		}
+		common := min(commonBits(n.prefix.Addr(), p.Addr()), n.prefix.Bits(), p.Bits())
+		switch {
+		case common == n.prefix.Bits() && common == p.Bits():
+			if !n.listed {
+				n.listed = true
+				t.size++
+			}
+			n.entry = n.entry.merge(e)
+			return
+		case common == n.prefix.Bits():
+			// p lies within n
+			link = &n.child[bitAt(p.Addr(), common)]
+			continue
+		case common == p.Bits():
+			// p contains n
+			parent := &prefixNode{prefix: p, listed: true, entry: e}
+			parent.child[bitAt(n.prefix.Addr(), common)] = n
+			*link = parent
+		default:
+			// Neither contains the other, join them under their common prefix
+			branch := &prefixNode{prefix: netip.PrefixFrom(p.Addr(), common).Masked()}
+			branch.child[bitAt(n.prefix.Addr(), common)] = n
+			branch.child[bitAt(p.Addr(), common)] = &prefixNode{prefix: p, listed: true, entry: e}
+			*link = branch
+		}
+		t.size++
+		return
+	}
+}
+
+// lookup calls fn for every listed prefix containing addr, shortest first
+func (t *prefixTree) lookup(addr netip.Addr, fn func(p netip.Prefix, e blockEntry)) {
+	for n := t.root; n != nil && n.prefix.Contains(addr); {
+		if n.listed {
+			fn(n.prefix, n.entry)
+		}
+		if n.prefix.Bits() == addr.BitLen() {
+			return
+		}
+		n = n.child[bitAt(addr, n.prefix.Bits())]
+	}
+}
+
+// bitAt returns bit i of addr, counting from the most significant
+func bitAt(addr netip.Addr, i int) int {
+	b := addr.As16()
+	if addr.Is4() {
+		i += 96
+	}
+	return int(b[i/8]>>(7-i%8)) & 1
+}
+
+// commonBits returns the number of leading bits a and b share
+func commonBits(a, b netip.Addr) int {
+	x, y := a.As16(), b.As16()
+	offset := 0
+	if a.Is4() {
+		offset = 96
+	}
+	for i := offset / 8; i < 16; i++ {
+		if diff := x[i] ^ y[i]; diff != 0 {
+			n := i*8 - offset
+			for diff&0x80 == 0 {
+				diff <<= 1
+				n++
+			}
+			return n
+		}
+	}
+	return 128 - offset
+}
+
+// walk calls fn for every listed prefix in address order, shorter prefixes
+// before the longer ones they contain
+func (t *prefixTree) walk(fn func(p netip.Prefix, e blockEntry)) {
+	var visit func(n *prefixNode)
+	visit = func(n *prefixNode) {
+		if n == nil {
+			return
+		}
+		if n.listed {
+			fn(n.prefix, n.entry)
+		}
+		visit(n.child[0])
+		visit(n.child[1])
+	}
+	visit(t.root)
+}
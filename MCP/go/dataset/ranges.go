@@ -0,0 +1,115 @@
+package dataset
+
+import (
+	"net/netip"
+	"slices"
+)
+
+// addrRange is an inclusive range of addresses of one family
+type addrRange struct {
+	from, to netip.Addr
+}
+
+// rangeSet is a set of addresses as sorted ranges that neither overlap nor
+// touch, so equal sets have equal ranges however they were listed
+type rangeSet []addrRange
+
+// newRangeSet returns the addresses covered by any of the prefixes
+func newRangeSet(prefixes []netip.Prefix) rangeSet {
+	ranges := make([]addrRange, len(prefixes))
+	for i, p := range prefixes {
+		ranges[i] = addrRange{p.Addr(), lastAddr(p)}
+	}
+	slices.SortFunc(ranges, func(a, b addrRange) int {
+		return a.from.Compare(b.from)
+	})
+	var s rangeSet
+	for _, r := range ranges {
+		if n := len(s); n > 0 && s[n-1].to.BitLen() == r.from.BitLen() && !follows(r.from, s[n-1].to) {
+			// Overlaps or touches the previous range
+			if s[n-1].to.Less(r.to) {
+				s[n-1].to = r.to
+			}
+			continue
+		}
+		s = append(s, r)
+	}
+	return s
+}
+
+// subtract returns the addresses of s that aren't in o
+func (s rangeSet) subtract(o rangeSet) rangeSet {
+	var out rangeSet
+	j := 0
+	for _, r := range s {
+		from := r.from
+		for ; j < len(o) && o[j].to.Less(from); j++ {
+		}
+		for k := j; k < len(o) && !r.to.Less(o[k].from); k++ {
+			if from.Less(o[k].from) {
+				out = append(out, addrRange{from, o[k].from.Prev()})
+			}
+			if !o[k].to.Less(r.to) {
+				from = netip.Addr{}
+				break
+			}
+			from = o[k].to.Next()
+		}
+		if from.IsValid() {
+			out = append(out, addrRange{from, r.to})
+		}
+	}
+	return out
+}
+
+// prefixes returns the fewest CIDRs covering exactly the addresses of s,
+// which merges adjacent listings into larger ranges
+func (s rangeSet) prefixes() []netip.Prefix {
+	var out []netip.Prefix
+	for _, r := range s {
+		from := r.from
+		for from.IsValid() && !r.to.Less(from) {
+			// The largest aligned prefix starting at from that ends within r
+			bits := from.BitLen()
+			for bits > 0 {
+				p := netip.PrefixFrom(from, bits-1)
+				if p.Masked().Addr() != from || r.to.Less(lastAddr(p)) {
+					break
+				}
+				bits--
+			}
+			p := netip.PrefixFrom(from, bits)
+			out = append(out, p)
+			last := lastAddr(p)
+			if last == r.to {
+				break
+			}
+			from = last.Next()
+		}
+	}
+	return out
+}
+
+// follows reports whether a comes after b with addresses in between
+func follows(a, b netip.Addr) bool {
+	next := b.Next()
+	return next.IsValid() && next.Less(a)
+}
+
+// lastAddr returns the highest address of p
+func lastAddr(p netip.Prefix) netip.Addr {
+	p = p.Masked()
+	b := p.Addr().As16()
+	offset := 0
+	if p.Addr().Is4() {
+		offset = 96
+	}
+	for i := offset + p.Bits(); i < 128; i++ {
+		b[i/8] |= 1 << (7 - i%8)
+	}
+	addr := netip.AddrFrom16(b)
+	if p.Addr().Is4() {
+		return addr.Unmap()
+	}
+	return addr
+}
@@ -0,0 +1,176 @@
+package dataset
+
+import (
+	"math/rand/v2"
+	"net/netip"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// prefixList parses space-separated prefixes
+func prefixList(t *testing.T, list string) []netip.Prefix {
+	t.Helper()
+	var out []netip.Prefix
+	for _, s := range strings.Fields(list) {
+		out = append(out, mustPrefix(t, s))
+	}
+	return out
+}
+
+func rangeStrings(s rangeSet) string {
+	out := make([]string, len(s))
+	for i, r := range s {
+		out[i] = r.from.String() + "-" + r.to.String()
+	}
+	return strings.Join(out, " ")
+}
+
+func TestNewRangeSet(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		prefixes string
+		want     string
+	}{
+		{"empty", "", ""},
+		{"overlapping", "10.0.0.0/24 10.0.0.128/25", "10.0.0.0-10.0.0.255"},
+		{"touching", "10.0.0.0/25 10.0.0.128/25", "10.0.0.0-10.0.0.255"},
+		{"touching single addresses", "10.0.0.2 10.0.0.1 10.0.0.3", "10.0.0.1-10.0.0.3"},
+		{"gap of one address", "10.0.0.1 10.0.0.3", "10.0.0.1-10.0.0.1 10.0.0.3-10.0.0.3"},
+		{"contained listed later", "10.0.0.0/8 10.1.0.0/16 10.255.255.255", "10.0.0.0-10.255.255.255"},
+		{"unsorted", "192.168.0.0/16 10.0.0.0/8", "10.0.0.0-10.255.255.255 192.168.0.0-192.168.255.255"},
+		// The last IPv4 address and the first IPv6 one are not adjacent
+		{"families stay apart", ":: 0.0.0.0/0", "0.0.0.0-255.255.255.255 ::-::"},
+		{"whole IPv6 space", "::/1 8000::/1", "::-ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"},
+	} {
+		if got := rangeStrings(newRangeSet(prefixList(t, tc.prefixes))); got != tc.want {
+			t.Errorf("%s: ranges = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestRangeSetSubtract(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		s, o    string
+		want    string // As the fewest CIDRs
+		wantLen int    // Count of CIDRs when want is too long to list
+	}{
+		{name: "nothing to subtract", s: "10.0.0.0/24", o: "", want: "10.0.0.0/24"},
+		{name: "everything", s: "10.0.0.0/24", o: "10.0.0.0/8", want: ""},
+		{name: "equal", s: "10.0.0.0/25 10.0.0.128/25", o: "10.0.0.0/24", want: ""},
+		{name: "hole in the middle", s: "10.0.0.0/24", o: "10.0.0.64/26", want: "10.0.0.0/26 10.0.0.128/25"},
+		{name: "first address", s: "10.0.0.0/24", o: "10.0.0.0", want: "10.0.0.1/32 10.0.0.2/31 10.0.0.4/30 10.0.0.8/29 10.0.0.16/28 10.0.0.32/27 10.0.0.64/26 10.0.0.128/25"},
+		{name: "overlapping the start", s: "10.0.0.128/25", o: "10.0.0.0/25 10.0.0.128/26", want: "10.0.0.192/26"},
+		{name: "several holes", s: "10.0.0.0/29", o: "10.0.0.1 10.0.0.3 10.0.0.6", want: "10.0.0.0/32 10.0.0.2/32 10.0.0.4/31 10.0.0.7/32"},
+		{name: "several ranges", s: "10.0.0.0/30 10.0.1.0/30", o: "10.0.0.2/31 10.0.1.0/31", want: "10.0.0.0/31 10.0.1.2/31"},
+		{name: "other family untouched", s: "10.0.0.0/24 2001:db8::/32", o: "::/0", want: "10.0.0.0/24"},
+		{name: "IPv4 space minus the last address", s: "0.0.0.0/0", o: "255.255.255.255", wantLen: 32},
+		{name: "IPv4 space minus the first address", s: "0.0.0.0/0", o: "0.0.0.0", wantLen: 32},
+		{name: "IPv6 space minus the last address", s: "::/0", o: "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", wantLen: 128},
+		{name: "IPv6 space minus the first address", s: "::/0", o: "::", wantLen: 128},
+	} {
+		s, o := newRangeSet(prefixList(t, tc.s)), newRangeSet(prefixList(t, tc.o))
+		got := cidrStrings(s.subtract(o).prefixes())
+		if tc.wantLen > 0 {
+			if len(got) != tc.wantLen {
+				t.Errorf("%s: %d CIDRs, want %d: %v", tc.name, len(got), tc.wantLen, got)
+			}
+			continue
+		}
+		if strings.Join(got, " ") != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, strings.Join(got, " "), tc.want)
+		}
+	}
+
+	// The ends of the address space are kept and dropped exactly
+	rest := newRangeSet(prefixList(t, "::/0")).subtract(newRangeSet(prefixList(t, "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff")))
+	if want := "::-ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffe"; rangeStrings(rest) != want {
+		t.Errorf("::/0 minus the last address = %s, want %s", rangeStrings(rest), want)
+	}
+	rest = newRangeSet(prefixList(t, "0.0.0.0/0")).subtract(newRangeSet(prefixList(t, "0.0.0.0")))
+	if want := "0.0.0.1-255.255.255.255"; rangeStrings(rest) != want {
+		t.Errorf("0.0.0.0/0 minus the first address = %s, want %s", rangeStrings(rest), want)
+	}
+}
+
+func TestRangeSetPrefixes(t *testing.T) {
+	for _, tc := range []struct {
+		prefixes string
+		want     string
+	}{
+		{"10.0.0.0/25 10.0.0.128/25", "10.0.0.0/24"},
+		{"10.0.0.1 10.0.0.2", "10.0.0.1/32 10.0.0.2/32"},
+		{"10.0.0.2 10.0.0.3", "10.0.0.2/31"},
+		{"0.0.0.0/1 128.0.0.0/1", "0.0.0.0/0"},
+		{"::/1 8000::/1", "::/0"},
+		{"10.0.0.0/24 2001:db8::/33 2001:db8:8000::/33", "10.0.0.0/24 2001:db8::/32"},
+	} {
+		if got := strings.Join(cidrStrings(newRangeSet(prefixList(t, tc.prefixes)).prefixes()), " "); got != tc.want {
+			t.Errorf("prefixes(%s) = %q, want %q", tc.prefixes, got, tc.want)
+		}
+	}
+}
+
+// TestRangeSetRandom checks subtract and prefixes against sets of addresses
+// counted one by one, within a small IPv4 block
+func TestRangeSetRandom(t *testing.T) {
+	rng := rand.New(rand.NewPCG(3, 4))
+	random := func() []netip.Prefix {
+		var out []netip.Prefix
+		for i := rng.IntN(12); i > 0; i-- {
+			addr := netip.AddrFrom4([4]byte{10, 0, 0, byte(rng.IntN(256))})
+			out = append(out, netip.PrefixFrom(addr, 24+rng.IntN(9)).Masked())
+		}
+		return out
+	}
+	members := func(prefixes []netip.Prefix) []netip.Addr {
+		var out []netip.Addr
+		for _, p := range prefixes {
+			for a := p.Addr(); p.Contains(a); a = a.Next() {
+				if !slices.Contains(out, a) {
+					out = append(out, a)
+				}
+			}
+		}
+		slices.SortFunc(out, netip.Addr.Compare)
+		return out
+	}
+
+	for i := 0; i < 500; i++ {
+		a, b := random(), random()
+		var want []netip.Addr
+		bAddrs := members(b)
+		for _, addr := range members(a) {
+			if !slices.Contains(bAddrs, addr) {
+				want = append(want, addr)
+			}
+		}
+		got := newRangeSet(a).subtract(newRangeSet(b)).prefixes()
+		if !slices.Equal(members(got), want) {
+			t.Fatalf("%v minus %v = %v", a, b, got)
+		}
+		// Minimal: no two CIDRs of the result could be one
+		for j := 1; j < len(got); j++ {
+			prev, cur := got[j-1], got[j]
+			if prev.Bits() == cur.Bits() && lastAddr(prev).Next() == cur.Addr() && netip.PrefixFrom(prev.Addr(), prev.Bits()-1).Masked().Addr() == prev.Addr() {
+				t.Fatalf("%v and %v of %v could be merged", prev, cur, got)
+			}
+		}
+	}
+}
+
+func TestLastAddr(t *testing.T) {
+	for in, want := range map[string]string{
+		"10.0.0.0/8":      "10.255.255.255",
+		"10.1.2.3/32":     "10.1.2.3",
+		"0.0.0.0/0":       "255.255.255.255",
+		"2001:db8::/32":   "2001:db8:ffff:ffff:ffff:ffff:ffff:ffff",
+		"::/0":            "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff",
+		"2001:db8::1/128": "2001:db8::1",
+	} {
+		if got := lastAddr(netip.MustParsePrefix(in)); got.String() != want {
+			t.Errorf("lastAddr(%s) = %s, want %s", in, got, want)
+		}
+	}
+}
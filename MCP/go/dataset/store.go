@@ -0,0 +1,107 @@
+package dataset
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// versionFormat names a version directory after the UTC time it was
+// downloaded, so the names sort by age
+const versionFormat = "20060102T150405Z"
+
+// A dataset directory holds one subdirectory per dataset with one
+// subdirectory per version, which holds the downloaded files:
+//
+//	<dir>/ip-blocklist/20261018T083500Z/ipv4.csv
+
+// saveVersion stores the files of a new version. They are written to a
+// temporary directory first, so a version directory is always complete.
+func saveVersion(dir, name string, downloaded time.Time, files map[string][]byte) (string, error) {
+	version := downloaded.UTC().Format(versionFormat)
+	parent := filepath.Join(dir, name)
+	if err := os.MkdirAll(parent, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create dataset directory: %w", err)
+	}
+	tmp, err := os.MkdirTemp(parent, "."+version+"-")
+	if err != nil {
+		return "", err
+	}
+	for file, data := range files {
+		if err := os.WriteFile(filepath.Join(tmp, file), data, 0o600); err != nil {
+			os.RemoveAll(tmp)
+			return "", err
+		}
+	}
+	if err := os.Rename(tmp, filepath.Join(parent, version)); err != nil {
+		os.RemoveAll(tmp)
+		return "", err
+	}
+	return version, nil
+}
+
+// listVersions returns the stored versions of a dataset, newest first
+func listVersions(dir, name string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(dir, name))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var versions []string
+	for _, e := range entries {
+		if _, err := time.Parse(versionFormat, e.Name()); err == nil && e.IsDir() {
+			versions = append(versions, e.Name())
+		}
+	}
+	slices.Sort(versions)
+	slices.Reverse(versions)
+	return versions, nil
+}
+
+// readVersion reads the named files of a stored version
+func readVersion(dir, name, version string, names []string) (map[string][]byte, error) {
+	files := make(map[string][]byte, len(names))
+	for _, file := range names {
+		data, err := os.ReadFile(filepath.Join(dir, name, version, file))
+		if err != nil {
+			return nil, err
+		}
+		files[file] = data
+	}
+	return files, nil
+}
+
+// pruneVersions deletes all but the newest keep versions, never the one in
+// use, and temporary directories left behind by interrupted downloads
+func pruneVersions(dir, name string, keep int, inUse string) error {
+	versions, err := listVersions(dir, name)
+	if err != nil {
+		return err
+	}
+	entries, _ := os.ReadDir(filepath.Join(dir, name))
+	for _, e := range entries {
+		info, err := e.Info()
+		if err == nil && e.Name()[0] == '.' && time.Since(info.ModTime()) > time.Hour {
+			os.RemoveAll(filepath.Join(dir, name, e.Name()))
+		}
+	}
+	for i, version := range versions {
+		if i < keep || version == inUse {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(dir, name, version)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// versionTime returns when a version was downloaded
+func versionTime(version string) time.Time {
+	t, _ := time.Parse(versionFormat, version)
+	return t
+}
@@ -2,93 +2,216 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
 	"log"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/neutrino-api/mcp-server/auth"
+	"github.com/neutrino-api/mcp-server/cache"
+	"github.com/neutrino-api/mcp-server/client"
 	"github.com/neutrino-api/mcp-server/config"
+	"github.com/neutrino-api/mcp-server/dataset"
+	"github.com/neutrino-api/mcp-server/models"
+	"github.com/neutrino-api/mcp-server/tenant"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			err := command(os.Args[2:], os.Stdout)
+			if err != nil && err != flag.ErrHelp {
+				log.Fatalf("%s: %v", os.Args[1], err)
+			}
+			return
+		}
+	}
+
+	opts, err := config.Init(os.Args[1:])
+	if err == flag.ErrHelp {
+		return
+	}
+	if err != nil {
+		log.Fatalf("Invalid command line: %v", err)
+	}
+	if opts.PrintConfig {
+		config.PrintConfig(os.Stdout)
+	}
+
 	cfg, err := config.LoadAPIConfig()
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
-
-	// Check transport environment variable (both uppercase and lowercase)
-	transport := os.Getenv("TRANSPORT")
-	if transport == "" {
-		transport = os.Getenv("transport")
+	clientCfg, err := config.LoadClientConfig()
+	if err != nil {
+		log.Fatalf("Failed to load HTTP client config: %v", err)
+	}
+	cacheCfg, err := config.LoadCacheConfig()
+	if err != nil {
+		log.Fatalf("Failed to load cache config: %v", err)
+	}
+	toolPolicy, err := config.LoadToolPolicy()
+	if err != nil {
+		log.Fatalf("Failed to load tool policy: %v", err)
 	}
+	datasetCfg, err := config.LoadDatasetConfig()
+	if err != nil {
+		log.Fatalf("Failed to load dataset config: %v", err)
+	}
+	tools, err := enabledTools(cfg, toolPolicy, datasetCfg)
+	if err != nil {
+		log.Fatalf("Invalid tool policy: %v", err)
+	}
+	if err := checkCacheTTLs(cfg, cacheCfg); err != nil {
+		log.Fatalf("Invalid cache config: %v", err)
+	}
+	if err := checkDatasets(cfg, datasetCfg); err != nil {
+		log.Fatalf("Invalid dataset config: %v", err)
+	}
+	if opts.PrintConfig {
+		// Printed above, loading the rest only validates it
+		if err := validateHTTPConfig(cfg); err != nil {
+			log.Fatalf("Invalid config: %v", err)
+		}
+		return
+	}
+	apiClient := client.New(clientCfg)
+	if cacheCfg.Enabled() {
+		store, err := newCacheStore(cacheCfg)
+		if err != nil {
+			log.Fatalf("Failed to open cache: %v", err)
+		}
+		apiClient.SetCache(store, cacheCfg)
+	}
+	apiClient.SetTools(tools)
+	client.SetDefault(apiClient)
+	for i := range tools {
+		if apiClient.CacheTTL(tools[i].Definition.Name) > 0 {
+			client.AddBypassCacheArgument(&tools[i].Definition)
+		}
+	}
+	startDatasets(cfg, datasetCfg)
+
+	transport := cfg.Transport
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
-	// HTTP/HTTPS Mode - if transport is "http", "HTTP", "https", or "HTTPS"
-	if transport == "http" || transport == "HTTP" || transport == "https" || transport == "HTTPS" {
+	// HTTP/HTTPS/SSE Mode - if transport is "http", "https", "sse" or "sse-https" (either case)
+	if config.IsHTTPTransport(transport) {
 		port := cfg.Port
 		if port == "" {
-			log.Fatalf("PORT environment variable is required for HTTP/HTTPS mode. Please set PORT environment variable.")
+			log.Fatalf("PORT is required for HTTP/HTTPS mode. Please set the PORT environment variable or the --port flag.")
 		}
 
-		// Determine if HTTPS mode and normalize transport
-		isHTTPS := transport == "https" || transport == "HTTPS"
-		if isHTTPS {
-			transport = "HTTPS"
-		} else {
-			transport = "HTTP"
-		}
+		// Determine if HTTPS and/or SSE mode and normalize transport
+		transport = strings.ToUpper(transport)
+		isHTTPS := transport == "HTTPS" || transport == "SSE-HTTPS"
+		isSSE := transport == "SSE" || transport == "SSE-HTTPS"
 		
 		log.Printf("Running in %s mode on port %s", transport, port)
 
-		mux := http.NewServeMux()
-		mux.HandleFunc("/mcp", func(w http.ResponseWriter, r *http.Request) {
-			// Read headers for dynamic config
-			apiCfg := &config.APIConfig{
-				BaseURL:     r.Header.Get("API_BASE_URL"),
-				BearerToken: r.Header.Get("BEARER_TOKEN"),
-				APIKey:      r.Header.Get("API_KEY"),
-				BasicAuth:   r.Header.Get("BASIC_AUTH"),
+		baseURLPolicy, err := config.LoadBaseURLPolicy(cfg)
+		if err != nil {
+			log.Fatalf("Failed to load base URL policy: %v", err)
+		}
+		authCfg, err := config.LoadAuthConfig()
+		if err != nil {
+			log.Fatalf("Failed to load auth config: %v", err)
+		}
+		tenants, err := config.LoadTenantRegistry()
+		if err != nil {
+			log.Fatalf("Failed to load tenants: %v", err)
+		}
+		if tenants != nil {
+			if !authCfg.Enabled() {
+				log.Fatalf("TENANTS_FILE requires inbound authentication, set AUTH_MODE")
 			}
-
-			if apiCfg.BaseURL == "" {
-				http.Error(w, "Missing API_BASE_URL header", http.StatusBadRequest)
-				return
+			if err := validateTenantTools(cfg, tenants); err != nil {
+				log.Fatalf("Invalid tenants file: %v", err)
 			}
+			log.Printf("Loaded %d tenants", len(tenants.Tenants()))
+		}
 
-			log.Printf("Incoming HTTP request - BaseURL: %s", apiCfg.BaseURL)
+		// One MCP server and transport for the lifetime of the process, so
+		// session IDs issued by the streamable transport stay valid. Credentials
+		// still arrive with every request and reach the tools via the request
+		// context, see withRequestConfig.
+		mcpSrv := createMCPServer(tools, transport)
+		mux := http.NewServeMux()
+		addr := net.JoinHostPort("0.0.0.0", port)
+		httpServer := &http.Server{Addr: addr, Handler: mux}
+
+		// Every MCP endpoint authenticates the client first, when enabled,
+		// then resolves the upstream API config for the request
+		mcpHandler := func(next http.Handler) http.Handler {
+			return withRequestConfig(cfg, baseURLPolicy, tenants, next)
+		}
+		var authenticator *auth.Authenticator
+		if authCfg.Enabled() {
+			authenticator, err = auth.New(authCfg)
+			if err != nil {
+				log.Fatalf("Failed to set up authentication: %v", err)
+			}
+			authenticator.RegisterMetadata(mux)
+			mcpHandler = func(next http.Handler) http.Handler {
+				return authenticator.Middleware(withRequestConfig(cfg, baseURLPolicy, tenants, next))
+			}
+			log.Printf("Inbound authentication enabled: %s", strings.Join(authCfg.Methods, ", "))
+		}
 
-			// Create MCP server for this request
-			mcpSrv := createMCPServer(apiCfg, transport)
-			handler := server.NewStreamableHTTPServer(mcpSrv, server.WithHTTPContextFunc(
-				func(ctx context.Context, req *http.Request) context.Context {
-					return context.WithValue(ctx, "apiConfig", apiCfg)
-				},
-			))
+		var sseServer *server.SSEServer
+		if isSSE {
+			// Older HTTP+SSE transport: clients hold a stream open on /sse and
+			// post their messages to /message
+			sseServer = server.NewSSEServer(mcpSrv,
+				server.WithHTTPServer(httpServer),
+				server.WithKeepAlive(true),
+			)
+			mux.Handle("/sse", mcpHandler(sseServer.SSEHandler()))
+			mux.Handle("/message", mcpHandler(sseServer.MessageHandler()))
+		} else {
+			streamable := server.NewStreamableHTTPServer(mcpSrv)
+			mux.Handle("/mcp", mcpHandler(streamable))
+		}
 
-			handler.ServeHTTP(w, r)
-		})
+		// Counters such as decode_failures per tool, only served to
+		// authenticated clients when authentication is enabled
+		metrics := client.MetricsHandler()
+		if authenticator != nil {
+			metrics = authenticator.Middleware(metrics)
+		}
+		mux.Handle("/debug/vars", metrics)
 
 		mux.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
 			w.Header().Set("Content-Type", "application/json")
-			w.Write([]byte(`{"status":"ok"}`))
+			if len(datasetCfg.Names) == 0 {
+				w.Write([]byte(`{"status":"ok"}`))
+				return
+			}
+			// Age and refresh status of the local datasets
+			json.NewEncoder(w).Encode(struct {
+				Status string `json:"status"`
+				dataset.Report
+			}{"ok", dataset.StatusReport()})
 		})
 
-		addr := net.JoinHostPort("0.0.0.0", port)
-		httpServer := &http.Server{Addr: addr, Handler: mux}
-
 		go func() {
 			// Check if HTTPS mode
 			if isHTTPS {
-				certFile := os.Getenv("CERT_FILE")
-				keyFile := os.Getenv("KEY_FILE")
+				certFile := cfg.CertFile
+				keyFile := cfg.KeyFile
 				
 				if certFile == "" || keyFile == "" {
-					log.Fatalf("CERT_FILE and KEY_FILE environment variables are required for HTTPS mode")
+					log.Fatalf("CERT_FILE and KEY_FILE are required for HTTPS mode")
 				}
 				
 				log.Printf("Starting HTTPS server on %s", addr)
@@ -108,7 +231,13 @@ func main() {
 
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
-		if err := httpServer.Shutdown(ctx); err != nil {
+		shutdown := httpServer.Shutdown
+		if sseServer != nil {
+			// Closes the open SSE streams, which would otherwise keep the
+			// HTTP server from shutting down, then the HTTP server itself
+			shutdown = sseServer.Shutdown
+		}
+		if err := shutdown(ctx); err != nil {
 			log.Printf("Shutdown error: %v", err)
 		} else {
 			log.Println("HTTP server shutdown complete")
@@ -118,7 +247,7 @@ func main() {
 
 	// STDIO Mode - default when no transport or transport is "stdio"
 	log.Println("Running in STDIO mode")
-	mcp := createMCPServer(cfg, "STDIO")
+	mcp := createMCPServer(tools, "STDIO")
 	go func() {
 		if err := server.ServeStdio(mcp); err != nil {
 			log.Fatalf("STDIO error: %v", err)
@@ -128,13 +257,20 @@ func main() {
 	log.Println("Received shutdown signal. Exiting STDIO mode.")
 }
 
-func createMCPServer(cfg *config.APIConfig, mode string) *server.MCPServer {
+func createMCPServer(tools []models.Tool, mode string) *server.MCPServer {
+	categories := make(map[string]string, len(tools))
+	for _, tool := range tools {
+		categories[tool.Definition.Name] = tool.Category
+	}
 	mcp := server.NewMCPServer("Neutrino API", "3.6.4",
 		server.WithToolCapabilities(true),
 		server.WithRecovery(),
+		// Tenant tool access and quotas, only in effect for requests that
+		// carry a tenant (HTTP mode with TENANTS_FILE)
+		server.WithToolHandlerMiddleware(tenant.Middleware(tenant.NewLimiter(), categories)),
+		server.WithToolFilter(tenant.Filter(categories)),
 	)
 
-	tools := GetAll(cfg)
 	log.Printf("Loaded %d tools for %s mode", len(tools), mode)
 
 	for _, tool := range tools {
@@ -142,4 +278,198 @@ func createMCPServer(cfg *config.APIConfig, mode string) *server.MCPServer {
 	}
 
 	return mcp
-}
\ No newline at end of file
+}
+
+// newCacheStore creates the in-memory cache, the persistent cache or both,
+// memory first. The persistent cache is compacted in the background.
+func newCacheStore(cfg *config.CacheConfig) (cache.Store, error) {
+	var tiers cache.Tiered
+	if cfg.MaxEntries > 0 {
+		tiers = append(tiers, cache.NewLRU(cfg.MaxEntries))
+	}
+	if cfg.Dir != "" {
+		disk, err := cache.OpenDisk(cfg.Dir, cfg.DiskMaxBytes)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Using persistent cache %s", disk.Path())
+		go func() {
+			for {
+				if err := disk.Compact(); err != nil {
+					log.Printf("Cache compaction failed: %v", err)
+				}
+				time.Sleep(cfg.CompactInterval)
+			}
+		}()
+		tiers = append(tiers, disk)
+	}
+	if len(tiers) == 1 {
+		return tiers[0], nil
+	}
+	return tiers, nil
+}
+
+// withRequestConfig builds the API config for each MCP request and attaches
+// it to the request context, where the tool handlers pick it up. With a
+// tenant registry the config comes from the authenticated client's tenant,
+// otherwise from the request headers. Requests for a base URL outside the
+// policy are rejected.
+func withRequestConfig(cfg *config.APIConfig, policy *config.BaseURLPolicy, tenants *config.TenantRegistry, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		p, authenticated := auth.PrincipalFromContext(ctx)
+
+		if tenants != nil {
+			if !authenticated {
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+			t, ok := tenants.Lookup(p.Tenant)
+			if !ok {
+				log.Printf("Rejected HTTP request from %s: principal %s has unknown tenant %q", r.RemoteAddr, p.Subject, p.Tenant)
+				http.Error(w, "Unknown tenant", http.StatusForbidden)
+				return
+			}
+			baseURL := t.BaseURL
+			if baseURL == "" {
+				baseURL = policy.Default
+			}
+			log.Printf("Incoming HTTP request - BaseURL: %s, Principal: %s, Tenant: %s", baseURL, p.Subject, t.Name)
+			// Credential headers are ignored, the tenant's own are used
+			apiCfg := &config.APIConfig{
+				BaseURL:   baseURL,
+				UserID:    t.UserID,
+				APIKey:    t.APIKey,
+				OutputDir: cfg.OutputDir,
+			}
+			ctx = config.WithTenant(config.WithAPIConfig(ctx, apiCfg), t)
+			next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+
+		baseURL, err := policy.Resolve(r.Header.Get("API_BASE_URL"))
+		if err != nil {
+			log.Printf("Rejected HTTP request from %s: %v", r.RemoteAddr, err)
+			http.Error(w, "API_BASE_URL not allowed", http.StatusForbidden)
+			return
+		}
+
+		if authenticated {
+			log.Printf("Incoming HTTP request - BaseURL: %s, Principal: %s, Tenant: %s", baseURL, p.Subject, p.Tenant)
+		} else {
+			log.Printf("Incoming HTTP request - BaseURL: %s", baseURL)
+		}
+		apiCfg := &config.APIConfig{
+			BaseURL:     baseURL,
+			BearerToken: r.Header.Get("BEARER_TOKEN"),
+			UserID:      r.Header.Get("USER_ID"),
+			APIKey:      r.Header.Get("API_KEY"),
+			BasicAuth:   r.Header.Get("BASIC_AUTH"),
+			// The output directory is server-side only and never taken from headers
+			OutputDir: cfg.OutputDir,
+		}
+		next.ServeHTTP(w, r.WithContext(config.WithAPIConfig(ctx, apiCfg)))
+	})
+}
+
+// validateHTTPConfig loads the settings only used in HTTP modes, so that
+// --print-config reports their errors too
+func validateHTTPConfig(cfg *config.APIConfig) error {
+	if _, err := config.LoadBaseURLPolicy(cfg); err != nil {
+		return err
+	}
+	if _, err := config.LoadAuthConfig(); err != nil {
+		return err
+	}
+	tenants, err := config.LoadTenantRegistry()
+	if err != nil || tenants == nil {
+		return err
+	}
+	return validateTenantTools(cfg, tenants)
+}
+
+// validateTenantTools checks that every tool pattern of a tenant matches a tool
+func validateTenantTools(cfg *config.APIConfig, tenants *config.TenantRegistry) error {
+	all := GetAll(cfg)
+	for _, t := range tenants.Tenants() {
+		if err := checkToolPatterns(t.Tools.Patterns(), all); err != nil {
+			return fmt.Errorf("tenant %q: %w", t.Name, err)
+		}
+	}
+	return nil
+}
+
+// enabledTools returns the tools the server-wide policy enables. Local tools
+// are left out unless their dataset is downloaded.
+func enabledTools(cfg *config.APIConfig, policy *config.ToolPolicy, datasetCfg *config.DatasetConfig) ([]models.Tool, error) {
+	all := GetAll(cfg)
+	if err := checkToolPatterns(policy.Patterns(), all); err != nil {
+		return nil, err
+	}
+	var tools []models.Tool
+	for _, tool := range all {
+		if tool.Dataset != "" && !datasetCfg.Enabled(tool.Dataset) {
+			continue
+		}
+		if policy.Allows(tool.Definition.Name, tool.Category) {
+			tools = append(tools, tool)
+		}
+	}
+	return tools, nil
+}
+
+// checkCacheTTLs reports cache TTLs for tools that don't exist or whose
+// responses are never cached, such as POST and binary tools
+func checkCacheTTLs(cfg *config.APIConfig, cacheCfg *config.CacheConfig) error {
+	all := GetAll(cfg)
+	for name, ttl := range cacheCfg.TTLs {
+		i := slices.IndexFunc(all, func(tool models.Tool) bool { return tool.Definition.Name == name })
+		if i < 0 {
+			return fmt.Errorf("CACHE_TTLS names unknown tool %q", name)
+		}
+		if ttl > 0 && !all[i].Cacheable {
+			return fmt.Errorf("invalid CACHE_TTLS entry %s=%s: %s is never cached", name, ttl, name)
+		}
+	}
+	return nil
+}
+
+// checkDatasets reports unknown datasets and missing credentials to
+// download them with
+func checkDatasets(cfg *config.APIConfig, datasetCfg *config.DatasetConfig) error {
+	for _, name := range datasetCfg.Names {
+		if _, ok := dataset.Lookup(name); !ok {
+			return fmt.Errorf("DATASETS names unknown dataset %q, expected one of %s", name, strings.Join(dataset.Names(), ", "))
+		}
+	}
+	if len(datasetCfg.Names) > 0 && cfg.UserID == "" && cfg.APIKey == "" && cfg.BearerToken == "" && cfg.BasicAuth == "" {
+		return fmt.Errorf("DATASETS requires the server's own API credentials, set USER_ID and API_KEY")
+	}
+	return nil
+}
+
+// startDatasets keeps the enabled datasets up to date in the background
+func startDatasets(cfg *config.APIConfig, datasetCfg *config.DatasetConfig) {
+	downloadCfg := *cfg
+	if downloadCfg.BaseURL == "" {
+		// HTTP modes take the base URL from each request
+		downloadCfg.BaseURL = config.DefaultBaseURL
+	}
+	for _, name := range datasetCfg.Names {
+		d, _ := dataset.Lookup(name)
+		go d.Run(context.Background(), &downloadCfg, datasetCfg)
+	}
+}
+
+// checkToolPatterns reports patterns that match no tool, most likely typos
+func checkToolPatterns(patterns []string, tools []models.Tool) error {
+	for _, pattern := range patterns {
+		matcher := config.ToolPolicy{Allow: []string{pattern}}
+		if !slices.ContainsFunc(tools, func(tool models.Tool) bool {
+			return matcher.Allows(tool.Definition.Name, tool.Category)
+		}) {
+			return fmt.Errorf("pattern %q matches no tool or category", pattern)
+		}
+	}
+	return nil
+}
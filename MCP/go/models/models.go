@@ -8,6 +8,9 @@ import (
 type Tool struct {
 	Definition mcp.Tool
 	Handler    func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error)
+	Category   string // The tools/ subpackage the tool belongs to, e.g. "geolocation"
+	Dataset    string // The downloaded dataset a local_ tool answers from, "*" for any, empty for API tools
+	Cacheable  bool   // The API request may be cached, coalesced and retried, which only GET lookups answering the same every time can
 }
 
 // VerifySecurityCodeResponse represents the VerifySecurityCodeResponse schema from the OpenAPI specification
@@ -17,7 +20,7 @@ type VerifySecurityCodeResponse struct {
 
 // BrowserBotResponse represents the BrowserBotResponse schema from the OpenAPI specification
 type BrowserBotResponse struct {
-	Exec_results []string `json:"exec-results"` // If you executed any JavaScript this array holds the results as objects
+	Exec_results []map[string]interface{} `json:"exec-results"` // If you executed any JavaScript this array holds the results as objects
 	Http_status_message string `json:"http-status-message"` // The HTTP status message the URL returned
 	Mime_type string `json:"mime-type"` // The document MIME type
 	Title string `json:"title"` // The document title
@@ -32,7 +35,7 @@ type BrowserBotResponse struct {
 	Load_time float64 `json:"load-time"` // The number of seconds taken to load the page (from initial request until DOM ready)
 	Response_headers map[string]interface{} `json:"response-headers"` // Map containing all the HTTP response headers the URL responded with
 	Url string `json:"url"` // The page URL
-	Elements []string `json:"elements"` // Array containing all the elements matching the supplied selector. <br>Each element object will contain the text content, HTML content and all current element attributes
+	Elements []map[string]interface{} `json:"elements"` // Array containing all the elements matching the supplied selector. <br>Each element object will contain the text content, HTML content and all current element attributes
 	Is_http_ok bool `json:"is-http-ok"` // True if the HTTP status is OK (200)
 	Http_redirect_url string `json:"http-redirect-url"` // The redirected URL if the URL responded with an HTTP redirect
 	Server_ip string `json:"server-ip"` // The HTTP servers IP address
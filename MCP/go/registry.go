@@ -9,6 +9,7 @@ import (
 	tools_data_tools "github.com/neutrino-api/mcp-server/tools/data_tools"
 	tools_telephony "github.com/neutrino-api/mcp-server/tools/telephony"
 	tools_geolocation "github.com/neutrino-api/mcp-server/tools/geolocation"
+	tools_imaging "github.com/neutrino-api/mcp-server/tools/imaging"
 )
 
 func GetAll(cfg *config.APIConfig) []models.Tool {
@@ -31,5 +32,20 @@ func GetAll(cfg *config.APIConfig) []models.Tool {
 		tools_security_and_networking.CreateIpprobeTool(cfg),
 		tools_security_and_networking.CreateEmailverifyTool(cfg),
 		tools_data_tools.CreateUalookupTool(cfg),
+		tools_data_tools.CreateBadwordfilterTool(cfg),
+		tools_www.CreateHtmlcleanTool(cfg),
+		tools_www.CreateBrowserbotTool(cfg),
+		tools_telephony.CreateSmsverifyTool(cfg),
+		tools_telephony.CreatePhoneverifyTool(cfg),
+		tools_telephony.CreatePhoneplaybackTool(cfg),
+		tools_imaging.CreateHtmlrenderTool(cfg),
+		tools_imaging.CreateImageresizeTool(cfg),
+		tools_imaging.CreateImagewatermarkTool(cfg),
+		tools_imaging.CreateQrcodeTool(cfg),
+		tools_e_commerce.CreateLocalBinlookupTool(cfg),
+		tools_security_and_networking.CreateLocalIpblocklistTool(cfg),
+		tools_security_and_networking.CreateLocalIpblocklistdiffTool(cfg),
+		tools_data_tools.CreateLocalDatasetstatusTool(cfg),
+		tools_security_and_networking.CreateLocalIpblocklistexportTool(cfg),
 	}
 }
@@ -0,0 +1,118 @@
+// Package tenant enforces the per-tenant tool access and quotas of the
+// tenant profiles in HTTP mode
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/neutrino-api/mcp-server/client"
+	"github.com/neutrino-api/mcp-server/config"
+)
+
+// Limiter counts tool calls per tenant in fixed one minute and one day
+// (UTC) windows
+type Limiter struct {
+	mu       sync.Mutex
+	counters map[string]*counter
+	now      func() time.Time
+}
+
+type counter struct {
+	minute      time.Time
+	minuteCount int
+	day         time.Time
+	dayCount    int
+}
+
+// NewLimiter creates an empty Limiter
+func NewLimiter() *Limiter {
+	return &Limiter{counters: map[string]*counter{}, now: time.Now}
+}
+
+// Allow records a call for t, or returns a quota_exceeded error without
+// recording it when either window is full
+func (l *Limiter) Allow(t *config.Tenant) error {
+	if t.Quota.RequestsPerMinute == 0 && t.Quota.RequestsPerDay == 0 {
+		return nil
+	}
+	now := l.now().UTC()
+	minute := now.Truncate(time.Minute)
+	day := now.Truncate(24 * time.Hour)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	c, ok := l.counters[t.Name]
+	if !ok {
+		c = &counter{}
+		l.counters[t.Name] = c
+	}
+	if !c.minute.Equal(minute) {
+		c.minute, c.minuteCount = minute, 0
+	}
+	if !c.day.Equal(day) {
+		c.day, c.dayCount = day, 0
+	}
+
+	if limit := t.Quota.RequestsPerDay; limit > 0 && c.dayCount >= limit {
+		return &client.ToolError{
+			Code:    client.CodeQuotaExceeded,
+			Message: fmt.Sprintf("tenant %s reached its limit of %d requests per day", t.Name, limit),
+		}
+	}
+	if limit := t.Quota.RequestsPerMinute; limit > 0 && c.minuteCount >= limit {
+		return &client.ToolError{
+			Code:      client.CodeQuotaExceeded,
+			Message:   fmt.Sprintf("tenant %s reached its limit of %d requests per minute", t.Name, limit),
+			Retryable: true,
+		}
+	}
+	c.minuteCount++
+	c.dayCount++
+	return nil
+}
+
+// Middleware rejects calls to tools the request's tenant hasn't enabled and
+// calls over the tenant's quota. Requests without a tenant pass through.
+// categories maps tool names to their category.
+func Middleware(limiter *Limiter, categories map[string]string) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			t, ok := config.TenantFromContext(ctx)
+			if !ok {
+				return next(ctx, request)
+			}
+			if !t.ToolEnabled(request.Params.Name, categories[request.Params.Name]) {
+				return client.ErrorResult(&client.ToolError{
+					Code:    client.CodeAuthFailed,
+					Message: fmt.Sprintf("tool %s is not enabled for tenant %s", request.Params.Name, t.Name),
+				}), nil
+			}
+			if err := limiter.Allow(t); err != nil {
+				return client.ErrorResult(err), nil
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+// Filter hides the tools the request's tenant hasn't enabled from tools/list
+func Filter(categories map[string]string) server.ToolFilterFunc {
+	return func(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+		t, ok := config.TenantFromContext(ctx)
+		if !ok {
+			return tools
+		}
+		enabled := make([]mcp.Tool, 0, len(tools))
+		for _, tool := range tools {
+			if t.ToolEnabled(tool.Name, categories[tool.Name]) {
+				enabled = append(enabled, tool)
+			}
+		}
+		return enabled
+	}
+}
@@ -0,0 +1,35 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/neutrino-api/mcp-server/client"
+	"github.com/neutrino-api/mcp-server/config"
+	"github.com/neutrino-api/mcp-server/models"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func BadwordfilterHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return client.JSONHandler[models.BadWordFilterResponse](cfg, client.Endpoint{
+		Method: http.MethodPost,
+		Path:   "/bad-word-filter",
+		Params: []string{"content", "catalog", "censor-character"},
+	})
+}
+
+func CreateBadwordfilterTool(cfg *config.APIConfig) models.Tool {
+	tool := mcp.NewTool("get_bad-word-filter",
+		mcp.WithDescription("Bad Word Filter"),
+		mcp.WithOutputSchema[models.BadWordFilterResponse](),
+		mcp.WithString("content", mcp.Required(), mcp.Description("The content to scan. This can be either a URL to load from, a file upload (multipart/form-data) or an HTML content string")),
+		mcp.WithString("catalog", mcp.Description("Which catalog of bad words to use, we currently maintain two bad word catalogs: <br> <ul> <li>strict - the largest database of bad words which includes profanity, obscenity, sexual, rude, cuss, dirty, swear and objectionable words and phrases. This catalog is suitable for environments of all ages including educational or children's content</li> <li>obscene - like the strict catalog but does not include any mild profanities, idiomatic phrases or words which are considered formal terminology. This catalog is suitable for adult environments where certain types of bad words are considered OK</li> </ul>")),
+		mcp.WithString("censor-character", mcp.Description("The character to use to censor out the bad words found")),
+	)
+
+	return models.Tool{
+		Definition: tool,
+		Handler:    BadwordfilterHandler(cfg),
+		Category:   "data_tools",
+	}
+}
@@ -2,79 +2,26 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"io"
 	"net/http"
-	"strings"
 
+	"github.com/neutrino-api/mcp-server/client"
 	"github.com/neutrino-api/mcp-server/config"
 	"github.com/neutrino-api/mcp-server/models"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 func EmailvalidateHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		args, ok := request.Params.Arguments.(map[string]any)
-		if !ok {
-			return mcp.NewToolResultError("Invalid arguments object"), nil
-		}
-		queryParams := make([]string, 0)
-		if val, ok := args["email"]; ok {
-			queryParams = append(queryParams, fmt.Sprintf("email=%v", val))
-		}
-		if val, ok := args["fix-typos"]; ok {
-			queryParams = append(queryParams, fmt.Sprintf("fix-typos=%v", val))
-		}
-		queryString := ""
-		if len(queryParams) > 0 {
-			queryString = "?" + strings.Join(queryParams, "&")
-		}
-		url := fmt.Sprintf("%s/email-validate%s", cfg.BaseURL, queryString)
-		req, err := http.NewRequest("GET", url, nil)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to create request", err), nil
-		}
-		// Set authentication based on auth type
-		// Fallback to single auth parameter
-		if cfg.APIKey != "" {
-			req.Header.Set("api-key", cfg.APIKey)
-		}
-		req.Header.Set("Accept", "application/json")
-
-		resp, err := http.DefaultClient.Do(req)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Request failed", err), nil
-		}
-		defer resp.Body.Close()
-
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to read response body", err), nil
-		}
-
-		if resp.StatusCode >= 400 {
-			return mcp.NewToolResultError(fmt.Sprintf("API error: %s", body)), nil
-		}
-		// Use properly typed response
-		var result models.EmailValidateResponse
-		if err := json.Unmarshal(body, &result); err != nil {
-			// Fallback to raw text if unmarshaling fails
-			return mcp.NewToolResultText(string(body)), nil
-		}
-
-		prettyJSON, err := json.MarshalIndent(result, "", "  ")
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to format JSON", err), nil
-		}
-
-		return mcp.NewToolResultText(string(prettyJSON)), nil
-	}
+	return client.JSONHandler[models.EmailValidateResponse](cfg, client.Endpoint{
+		Method: http.MethodGet,
+		Path:   "/email-validate",
+		Params: []string{"email", "fix-typos"},
+	})
 }
 
 func CreateEmailvalidateTool(cfg *config.APIConfig) models.Tool {
 	tool := mcp.NewTool("get_email-validate",
 		mcp.WithDescription("Email Validate"),
+		mcp.WithOutputSchema[models.EmailValidateResponse](),
 		mcp.WithString("email", mcp.Required(), mcp.Description("An email address")),
 		mcp.WithBoolean("fix-typos", mcp.Description("Automatically attempt to fix typos in the address")),
 	)
@@ -82,5 +29,7 @@ func CreateEmailvalidateTool(cfg *config.APIConfig) models.Tool {
 	return models.Tool{
 		Definition: tool,
 		Handler:    EmailvalidateHandler(cfg),
+		Category:   "data_tools",
+		Cacheable:  true,
 	}
 }
@@ -0,0 +1,31 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/neutrino-api/mcp-server/client"
+	"github.com/neutrino-api/mcp-server/config"
+	"github.com/neutrino-api/mcp-server/dataset"
+	"github.com/neutrino-api/mcp-server/models"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func LocalDatasetstatusHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return client.JSONResult(dataset.StatusReport()), nil
+	}
+}
+
+func CreateLocalDatasetstatusTool(cfg *config.APIConfig) models.Tool {
+	tool := mcp.NewTool("local_dataset-status",
+		mcp.WithDescription("Status of the downloaded datasets the local_ tools answer from: the version in use, its age and record count, the last refresh and its error, the next refresh and the versions kept on disk"),
+		mcp.WithOutputSchema[dataset.Report](),
+	)
+
+	return models.Tool{
+		Definition: tool,
+		Handler:    LocalDatasetstatusHandler(cfg),
+		Category:   "data_tools",
+		Dataset:    "*",
+	}
+}
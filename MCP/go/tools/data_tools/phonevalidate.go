@@ -2,82 +2,26 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"io"
 	"net/http"
-	"strings"
 
+	"github.com/neutrino-api/mcp-server/client"
 	"github.com/neutrino-api/mcp-server/config"
 	"github.com/neutrino-api/mcp-server/models"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 func PhonevalidateHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		args, ok := request.Params.Arguments.(map[string]any)
-		if !ok {
-			return mcp.NewToolResultError("Invalid arguments object"), nil
-		}
-		queryParams := make([]string, 0)
-		if val, ok := args["number"]; ok {
-			queryParams = append(queryParams, fmt.Sprintf("number=%v", val))
-		}
-		if val, ok := args["country-code"]; ok {
-			queryParams = append(queryParams, fmt.Sprintf("country-code=%v", val))
-		}
-		if val, ok := args["ip"]; ok {
-			queryParams = append(queryParams, fmt.Sprintf("ip=%v", val))
-		}
-		queryString := ""
-		if len(queryParams) > 0 {
-			queryString = "?" + strings.Join(queryParams, "&")
-		}
-		url := fmt.Sprintf("%s/phone-validate%s", cfg.BaseURL, queryString)
-		req, err := http.NewRequest("GET", url, nil)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to create request", err), nil
-		}
-		// Set authentication based on auth type
-		// Fallback to single auth parameter
-		if cfg.APIKey != "" {
-			req.Header.Set("api-key", cfg.APIKey)
-		}
-		req.Header.Set("Accept", "application/json")
-
-		resp, err := http.DefaultClient.Do(req)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Request failed", err), nil
-		}
-		defer resp.Body.Close()
-
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to read response body", err), nil
-		}
-
-		if resp.StatusCode >= 400 {
-			return mcp.NewToolResultError(fmt.Sprintf("API error: %s", body)), nil
-		}
-		// Use properly typed response
-		var result models.PhoneValidateResponse
-		if err := json.Unmarshal(body, &result); err != nil {
-			// Fallback to raw text if unmarshaling fails
-			return mcp.NewToolResultText(string(body)), nil
-		}
-
-		prettyJSON, err := json.MarshalIndent(result, "", "  ")
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to format JSON", err), nil
-		}
-
-		return mcp.NewToolResultText(string(prettyJSON)), nil
-	}
+	return client.JSONHandler[models.PhoneValidateResponse](cfg, client.Endpoint{
+		Method: http.MethodGet,
+		Path:   "/phone-validate",
+		Params: []string{"number", "country-code", "ip"},
+	})
 }
 
 func CreatePhonevalidateTool(cfg *config.APIConfig) models.Tool {
 	tool := mcp.NewTool("get_phone-validate",
 		mcp.WithDescription("Phone Validate"),
+		mcp.WithOutputSchema[models.PhoneValidateResponse](),
 		mcp.WithString("number", mcp.Required(), mcp.Description("A phone number. This can be in international format (E.164) or local format. If passing local format you must also set either the 'country-code' OR 'ip' options as well")),
 		mcp.WithString("country-code", mcp.Description("ISO 2-letter country code, assume numbers are based in this country. If not set numbers are assumed to be in international format (with or without the leading + sign)")),
 		mcp.WithString("ip", mcp.Description("Pass in a users IP address and we will assume numbers are based in the country of the IP address")),
@@ -86,5 +30,7 @@ func CreatePhonevalidateTool(cfg *config.APIConfig) models.Tool {
 	return models.Tool{
 		Definition: tool,
 		Handler:    PhonevalidateHandler(cfg),
+		Category:   "data_tools",
+		Cacheable:  true,
 	}
 }
@@ -2,79 +2,26 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"io"
 	"net/http"
-	"strings"
 
+	"github.com/neutrino-api/mcp-server/client"
 	"github.com/neutrino-api/mcp-server/config"
 	"github.com/neutrino-api/mcp-server/models"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 func BinlookupHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		args, ok := request.Params.Arguments.(map[string]any)
-		if !ok {
-			return mcp.NewToolResultError("Invalid arguments object"), nil
-		}
-		queryParams := make([]string, 0)
-		if val, ok := args["bin-number"]; ok {
-			queryParams = append(queryParams, fmt.Sprintf("bin-number=%v", val))
-		}
-		if val, ok := args["customer-ip"]; ok {
-			queryParams = append(queryParams, fmt.Sprintf("customer-ip=%v", val))
-		}
-		queryString := ""
-		if len(queryParams) > 0 {
-			queryString = "?" + strings.Join(queryParams, "&")
-		}
-		url := fmt.Sprintf("%s/bin-lookup%s", cfg.BaseURL, queryString)
-		req, err := http.NewRequest("GET", url, nil)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to create request", err), nil
-		}
-		// Set authentication based on auth type
-		// Fallback to single auth parameter
-		if cfg.APIKey != "" {
-			req.Header.Set("api-key", cfg.APIKey)
-		}
-		req.Header.Set("Accept", "application/json")
-
-		resp, err := http.DefaultClient.Do(req)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Request failed", err), nil
-		}
-		defer resp.Body.Close()
-
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to read response body", err), nil
-		}
-
-		if resp.StatusCode >= 400 {
-			return mcp.NewToolResultError(fmt.Sprintf("API error: %s", body)), nil
-		}
-		// Use properly typed response
-		var result models.BINLookupResponse
-		if err := json.Unmarshal(body, &result); err != nil {
-			// Fallback to raw text if unmarshaling fails
-			return mcp.NewToolResultText(string(body)), nil
-		}
-
-		prettyJSON, err := json.MarshalIndent(result, "", "  ")
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to format JSON", err), nil
-		}
-
-		return mcp.NewToolResultText(string(prettyJSON)), nil
-	}
+	return client.JSONHandler[models.BINLookupResponse](cfg, client.Endpoint{
+		Method: http.MethodGet,
+		Path:   "/bin-lookup",
+		Params: []string{"bin-number", "customer-ip"},
+	})
 }
 
 func CreateBinlookupTool(cfg *config.APIConfig) models.Tool {
 	tool := mcp.NewTool("get_bin-lookup",
 		mcp.WithDescription("BIN Lookup"),
+		mcp.WithOutputSchema[models.BINLookupResponse](),
 		mcp.WithString("bin-number", mcp.Required(), mcp.Description("The BIN or IIN number. This is the first 6, 8 or 10 digits of a card number, use 8 (or more) digits for the highest level of accuracy")),
 		mcp.WithString("customer-ip", mcp.Description("Pass in the customers IP address and we will return some extra information about them")),
 	)
@@ -82,5 +29,7 @@ func CreateBinlookupTool(cfg *config.APIConfig) models.Tool {
 	return models.Tool{
 		Definition: tool,
 		Handler:    BinlookupHandler(cfg),
+		Category:   "e_commerce",
+		Cacheable:  true,
 	}
 }
@@ -2,82 +2,26 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"io"
 	"net/http"
-	"strings"
 
+	"github.com/neutrino-api/mcp-server/client"
 	"github.com/neutrino-api/mcp-server/config"
 	"github.com/neutrino-api/mcp-server/models"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 func ConvertHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		args, ok := request.Params.Arguments.(map[string]any)
-		if !ok {
-			return mcp.NewToolResultError("Invalid arguments object"), nil
-		}
-		queryParams := make([]string, 0)
-		if val, ok := args["from-value"]; ok {
-			queryParams = append(queryParams, fmt.Sprintf("from-value=%v", val))
-		}
-		if val, ok := args["from-type"]; ok {
-			queryParams = append(queryParams, fmt.Sprintf("from-type=%v", val))
-		}
-		if val, ok := args["to-type"]; ok {
-			queryParams = append(queryParams, fmt.Sprintf("to-type=%v", val))
-		}
-		queryString := ""
-		if len(queryParams) > 0 {
-			queryString = "?" + strings.Join(queryParams, "&")
-		}
-		url := fmt.Sprintf("%s/convert%s", cfg.BaseURL, queryString)
-		req, err := http.NewRequest("GET", url, nil)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to create request", err), nil
-		}
-		// Set authentication based on auth type
-		// Fallback to single auth parameter
-		if cfg.APIKey != "" {
-			req.Header.Set("api-key", cfg.APIKey)
-		}
-		req.Header.Set("Accept", "application/json")
-
-		resp, err := http.DefaultClient.Do(req)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Request failed", err), nil
-		}
-		defer resp.Body.Close()
-
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to read response body", err), nil
-		}
-
-		if resp.StatusCode >= 400 {
-			return mcp.NewToolResultError(fmt.Sprintf("API error: %s", body)), nil
-		}
-		// Use properly typed response
-		var result models.ConvertResponse
-		if err := json.Unmarshal(body, &result); err != nil {
-			// Fallback to raw text if unmarshaling fails
-			return mcp.NewToolResultText(string(body)), nil
-		}
-
-		prettyJSON, err := json.MarshalIndent(result, "", "  ")
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to format JSON", err), nil
-		}
-
-		return mcp.NewToolResultText(string(prettyJSON)), nil
-	}
+	return client.JSONHandler[models.ConvertResponse](cfg, client.Endpoint{
+		Method: http.MethodGet,
+		Path:   "/convert",
+		Params: []string{"from-value", "from-type", "to-type"},
+	})
 }
 
 func CreateConvertTool(cfg *config.APIConfig) models.Tool {
 	tool := mcp.NewTool("get_convert",
 		mcp.WithDescription("Convert"),
+		mcp.WithOutputSchema[models.ConvertResponse](),
 		mcp.WithString("from-value", mcp.Required(), mcp.Description("The value to convert from (e.g. 10.95)")),
 		mcp.WithString("from-type", mcp.Required(), mcp.Description("The type of the value to convert from (e.g. USD)")),
 		mcp.WithString("to-type", mcp.Required(), mcp.Description("The type to convert to (e.g. EUR)")),
@@ -86,5 +30,7 @@ func CreateConvertTool(cfg *config.APIConfig) models.Tool {
 	return models.Tool{
 		Definition: tool,
 		Handler:    ConvertHandler(cfg),
+		Category:   "e_commerce",
+		Cacheable:  true,
 	}
 }
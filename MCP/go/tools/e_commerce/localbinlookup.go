@@ -0,0 +1,44 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/neutrino-api/mcp-server/client"
+	"github.com/neutrino-api/mcp-server/config"
+	"github.com/neutrino-api/mcp-server/dataset"
+	"github.com/neutrino-api/mcp-server/models"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func LocalBinlookupHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		table, err := dataset.BINList.Get()
+		if err != nil {
+			return client.ErrorResult(err), nil
+		}
+		number, err := request.RequireString("bin-number")
+		if err != nil {
+			return client.ErrorResult(&client.ToolError{Code: client.CodeInvalidArgument, Message: err.Error()}), nil
+		}
+		result, err := table.Lookup(number)
+		if err != nil {
+			return client.ErrorResult(&client.ToolError{Code: client.CodeInvalidArgument, Message: err.Error()}), nil
+		}
+		return client.JSONResult(result), nil
+	}
+}
+
+func CreateLocalBinlookupTool(cfg *config.APIConfig) models.Tool {
+	tool := mcp.NewTool("local_bin-lookup",
+		mcp.WithDescription("BIN Lookup answered from the downloaded BIN list, without calling the API. The longest matching BIN of 6 to 11 digits is used. The IP fields are never set"),
+		mcp.WithOutputSchema[models.BINLookupResponse](),
+		mcp.WithString("bin-number", mcp.Required(), mcp.Description("The BIN or IIN number, or a whole card number. Spaces and dashes are ignored")),
+	)
+
+	return models.Tool{
+		Definition: tool,
+		Handler:    LocalBinlookupHandler(cfg),
+		Category:   "e_commerce",
+		Dataset:    "bin-list",
+	}
+}
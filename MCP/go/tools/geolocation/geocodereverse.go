@@ -2,85 +2,26 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"io"
 	"net/http"
-	"strings"
 
+	"github.com/neutrino-api/mcp-server/client"
 	"github.com/neutrino-api/mcp-server/config"
 	"github.com/neutrino-api/mcp-server/models"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 func GeocodereverseHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		args, ok := request.Params.Arguments.(map[string]any)
-		if !ok {
-			return mcp.NewToolResultError("Invalid arguments object"), nil
-		}
-		queryParams := make([]string, 0)
-		if val, ok := args["latitude"]; ok {
-			queryParams = append(queryParams, fmt.Sprintf("latitude=%v", val))
-		}
-		if val, ok := args["longitude"]; ok {
-			queryParams = append(queryParams, fmt.Sprintf("longitude=%v", val))
-		}
-		if val, ok := args["language-code"]; ok {
-			queryParams = append(queryParams, fmt.Sprintf("language-code=%v", val))
-		}
-		if val, ok := args["zoom"]; ok {
-			queryParams = append(queryParams, fmt.Sprintf("zoom=%v", val))
-		}
-		queryString := ""
-		if len(queryParams) > 0 {
-			queryString = "?" + strings.Join(queryParams, "&")
-		}
-		url := fmt.Sprintf("%s/geocode-reverse%s", cfg.BaseURL, queryString)
-		req, err := http.NewRequest("GET", url, nil)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to create request", err), nil
-		}
-		// Set authentication based on auth type
-		// Fallback to single auth parameter
-		if cfg.APIKey != "" {
-			req.Header.Set("api-key", cfg.APIKey)
-		}
-		req.Header.Set("Accept", "application/json")
-
-		resp, err := http.DefaultClient.Do(req)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Request failed", err), nil
-		}
-		defer resp.Body.Close()
-
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to read response body", err), nil
-		}
-
-		if resp.StatusCode >= 400 {
-			return mcp.NewToolResultError(fmt.Sprintf("API error: %s", body)), nil
-		}
-		// Use properly typed response
-		var result models.GeocodeReverseResponse
-		if err := json.Unmarshal(body, &result); err != nil {
-			// Fallback to raw text if unmarshaling fails
-			return mcp.NewToolResultText(string(body)), nil
-		}
-
-		prettyJSON, err := json.MarshalIndent(result, "", "  ")
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to format JSON", err), nil
-		}
-
-		return mcp.NewToolResultText(string(prettyJSON)), nil
-	}
+	return client.JSONHandler[models.GeocodeReverseResponse](cfg, client.Endpoint{
+		Method: http.MethodGet,
+		Path:   "/geocode-reverse",
+		Params: []string{"latitude", "longitude", "language-code", "zoom"},
+	})
 }
 
 func CreateGeocodereverseTool(cfg *config.APIConfig) models.Tool {
 	tool := mcp.NewTool("get_geocode-reverse",
 		mcp.WithDescription("Geocode Reverse"),
+		mcp.WithOutputSchema[models.GeocodeReverseResponse](),
 		mcp.WithString("latitude", mcp.Required(), mcp.Description("The location latitude in decimal degrees format")),
 		mcp.WithString("longitude", mcp.Required(), mcp.Description("The location longitude in decimal degrees format")),
 		mcp.WithString("language-code", mcp.Description("The language to display results in, available languages are: <ul> <li>de, en, es, fr, it, pt, ru</li> </ul>")),
@@ -90,5 +31,7 @@ func CreateGeocodereverseTool(cfg *config.APIConfig) models.Tool {
 	return models.Tool{
 		Definition: tool,
 		Handler:    GeocodereverseHandler(cfg),
+		Category:   "geolocation",
+		Cacheable:  true,
 	}
 }
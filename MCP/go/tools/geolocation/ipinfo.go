@@ -2,79 +2,26 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"io"
 	"net/http"
-	"strings"
 
+	"github.com/neutrino-api/mcp-server/client"
 	"github.com/neutrino-api/mcp-server/config"
 	"github.com/neutrino-api/mcp-server/models"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 func IpinfoHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		args, ok := request.Params.Arguments.(map[string]any)
-		if !ok {
-			return mcp.NewToolResultError("Invalid arguments object"), nil
-		}
-		queryParams := make([]string, 0)
-		if val, ok := args["ip"]; ok {
-			queryParams = append(queryParams, fmt.Sprintf("ip=%v", val))
-		}
-		if val, ok := args["reverse-lookup"]; ok {
-			queryParams = append(queryParams, fmt.Sprintf("reverse-lookup=%v", val))
-		}
-		queryString := ""
-		if len(queryParams) > 0 {
-			queryString = "?" + strings.Join(queryParams, "&")
-		}
-		url := fmt.Sprintf("%s/ip-info%s", cfg.BaseURL, queryString)
-		req, err := http.NewRequest("GET", url, nil)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to create request", err), nil
-		}
-		// Set authentication based on auth type
-		// Fallback to single auth parameter
-		if cfg.APIKey != "" {
-			req.Header.Set("api-key", cfg.APIKey)
-		}
-		req.Header.Set("Accept", "application/json")
-
-		resp, err := http.DefaultClient.Do(req)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Request failed", err), nil
-		}
-		defer resp.Body.Close()
-
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to read response body", err), nil
-		}
-
-		if resp.StatusCode >= 400 {
-			return mcp.NewToolResultError(fmt.Sprintf("API error: %s", body)), nil
-		}
-		// Use properly typed response
-		var result models.IPInfoResponse
-		if err := json.Unmarshal(body, &result); err != nil {
-			// Fallback to raw text if unmarshaling fails
-			return mcp.NewToolResultText(string(body)), nil
-		}
-
-		prettyJSON, err := json.MarshalIndent(result, "", "  ")
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to format JSON", err), nil
-		}
-
-		return mcp.NewToolResultText(string(prettyJSON)), nil
-	}
+	return client.JSONHandler[models.IPInfoResponse](cfg, client.Endpoint{
+		Method: http.MethodGet,
+		Path:   "/ip-info",
+		Params: []string{"ip", "reverse-lookup"},
+	})
 }
 
 func CreateIpinfoTool(cfg *config.APIConfig) models.Tool {
 	tool := mcp.NewTool("get_ip-info",
 		mcp.WithDescription("IP Info"),
+		mcp.WithOutputSchema[models.IPInfoResponse](),
 		mcp.WithString("ip", mcp.Required(), mcp.Description("IPv4 or IPv6 address")),
 		mcp.WithBoolean("reverse-lookup", mcp.Description("Do a reverse DNS (PTR) lookup. This option can add extra delay to the request so only use it if you need it")),
 	)
@@ -82,5 +29,7 @@ func CreateIpinfoTool(cfg *config.APIConfig) models.Tool {
 	return models.Tool{
 		Definition: tool,
 		Handler:    IpinfoHandler(cfg),
+		Category:   "geolocation",
+		Cacheable:  true,
 	}
 }
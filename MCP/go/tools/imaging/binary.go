@@ -0,0 +1,80 @@
+package tools
+
+import (
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/neutrino-api/mcp-server/client"
+	"github.com/neutrino-api/mcp-server/config"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// binaryResult converts a binary API response into MCP content. Images are
+// returned as image content and anything else (e.g. PDF) as an embedded blob
+// resource. When save is set the bytes are written to cfg.OutputDir instead
+// and the path of the new file is returned.
+func binaryResult(cfg *config.APIConfig, name string, contentType string, body []byte, save bool) *mcp.CallToolResult {
+	mimeType := detectMimeType(contentType, body)
+
+	if save {
+		if cfg.OutputDir == "" {
+			return client.ErrorResult(&client.ToolError{Code: client.CodeInvalidArgument, Message: "save-to-file requested but no output directory is configured (set OUTPUT_DIR)"})
+		}
+		f, err := os.CreateTemp(cfg.OutputDir, name+"-*"+extensionFor(mimeType))
+		if err != nil {
+			return client.ErrorResult(&client.ToolError{Code: client.CodeInternal, Message: "Failed to create output file: " + err.Error()})
+		}
+		_, err = f.Write(body)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(f.Name())
+			return client.ErrorResult(&client.ToolError{Code: client.CodeInternal, Message: "Failed to write output file: " + err.Error()})
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Saved %d bytes (%s) to %s", len(body), mimeType, f.Name()))
+	}
+
+	data := base64.StdEncoding.EncodeToString(body)
+	if strings.HasPrefix(mimeType, "image/") {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{mcp.NewImageContent(data, mimeType)},
+		}
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{mcp.NewEmbeddedResource(mcp.BlobResourceContents{
+			URI:      fmt.Sprintf("neutrino://%s/output%s", name, extensionFor(mimeType)),
+			MIMEType: mimeType,
+			Blob:     data,
+		})},
+	}
+}
+
+// detectMimeType prefers the Content-Type header returned by the API and
+// falls back to sniffing the body when the header is missing or generic.
+func detectMimeType(contentType string, body []byte) string {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil && mediaType != "application/octet-stream" {
+		return mediaType
+	}
+	mediaType, _, _ := mime.ParseMediaType(http.DetectContentType(body))
+	return mediaType
+}
+
+func extensionFor(mimeType string) string {
+	switch mimeType {
+	case "application/pdf":
+		return ".pdf"
+	case "image/png":
+		return ".png"
+	case "image/jpeg":
+		return ".jpg"
+	}
+	if exts, err := mime.ExtensionsByType(mimeType); err == nil && len(exts) > 0 {
+		return exts[0]
+	}
+	return ".bin"
+}
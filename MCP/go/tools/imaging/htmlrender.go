@@ -0,0 +1,58 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/neutrino-api/mcp-server/client"
+	"github.com/neutrino-api/mcp-server/config"
+	"github.com/neutrino-api/mcp-server/models"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func HtmlrenderHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return client.RawHandler(cfg, client.Endpoint{
+		Method: http.MethodPost,
+		Path:   "/html-render",
+		Params: []string{"content", "css", "delay", "footer", "format", "grayscale", "header", "ignore-certificate-errors", "image-height", "image-width", "landscape", "margin", "margin-bottom", "margin-left", "margin-right", "margin-top", "page-height", "page-size", "page-width", "timeout", "title", "zoom"},
+		Accept: "*/*",
+	}, func(args map[string]any, resp *client.Response) *mcp.CallToolResult {
+		save, _ := args["save-to-file"].(bool)
+		return binaryResult(cfg, "html-render", resp.Header.Get("Content-Type"), resp.Body, save)
+	})
+}
+
+func CreateHtmlrenderTool(cfg *config.APIConfig) models.Tool {
+	tool := mcp.NewTool("get_html-render",
+		mcp.WithDescription("HTML Render"),
+		mcp.WithString("content", mcp.Required(), mcp.Description("The HTML content. This can be either a URL to load from, a file upload (multipart/form-data) or an HTML content string")),
+		mcp.WithString("css", mcp.Description("Inject custom CSS into the HTML. e.g. 'body { background-color: red;}'")),
+		mcp.WithNumber("delay", mcp.Description("Number of seconds to wait before rendering the page (can be useful for pages with animations etc)")),
+		mcp.WithString("footer", mcp.Description("The footer HTML to insert into each page. The following dynamic tags are supported: {date}, {title}, {url}, {pageNumber}, {totalPages}")),
+		mcp.WithString("format", mcp.Description("Which format to output, available options are: PDF, PNG, JPG")),
+		mcp.WithBoolean("grayscale", mcp.Description("Render the final document in grayscale")),
+		mcp.WithString("header", mcp.Description("The header HTML to insert into each page. The following dynamic tags are supported: {date}, {title}, {url}, {pageNumber}, {totalPages}")),
+		mcp.WithBoolean("ignore-certificate-errors", mcp.Description("Ignore any TLS/SSL certificate errors")),
+		mcp.WithNumber("image-height", mcp.Description("If rendering to an image format (PNG or JPG) use this image height (in pixels). The default is automatic which dynamically sets the image height based on the content")),
+		mcp.WithNumber("image-width", mcp.Description("If rendering to an image format (PNG or JPG) use this image width (in pixels)")),
+		mcp.WithBoolean("landscape", mcp.Description("Set the document to landscape orientation")),
+		mcp.WithNumber("margin", mcp.Description("The document margin (in mm)")),
+		mcp.WithNumber("margin-bottom", mcp.Description("The document bottom margin (in mm)")),
+		mcp.WithNumber("margin-left", mcp.Description("The document left margin (in mm)")),
+		mcp.WithNumber("margin-right", mcp.Description("The document right margin (in mm)")),
+		mcp.WithNumber("margin-top", mcp.Description("The document top margin (in mm)")),
+		mcp.WithNumber("page-height", mcp.Description("Set the PDF page height explicitly (in mm)")),
+		mcp.WithString("page-size", mcp.Description("Set the document page size, can be one of: A0 - A9, B0 - B10, Comm10E, DLE or Letter")),
+		mcp.WithNumber("page-width", mcp.Description("Set the PDF page width explicitly (in mm)")),
+		mcp.WithNumber("timeout", mcp.Description("Timeout in seconds. Give up if still trying to load the HTML content after this number of seconds")),
+		mcp.WithString("title", mcp.Description("The document title")),
+		mcp.WithNumber("zoom", mcp.Description("Set the zoom factor when rendering the page (2.0 for double size, 0.5 for half size)")),
+		mcp.WithBoolean("save-to-file", mcp.Description("Write the output to the server's configured output directory and return the file path instead of the file content")),
+	)
+
+	return models.Tool{
+		Definition: tool,
+		Handler:    HtmlrenderHandler(cfg),
+		Category:   "imaging",
+	}
+}
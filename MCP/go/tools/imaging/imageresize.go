@@ -0,0 +1,42 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/neutrino-api/mcp-server/client"
+	"github.com/neutrino-api/mcp-server/config"
+	"github.com/neutrino-api/mcp-server/models"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func ImageresizeHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return client.RawHandler(cfg, client.Endpoint{
+		Method: http.MethodPost,
+		Path:   "/image-resize",
+		Params: []string{"image-url", "width", "bg-color", "format", "height", "resize-mode"},
+		Accept: "*/*",
+	}, func(args map[string]any, resp *client.Response) *mcp.CallToolResult {
+		save, _ := args["save-to-file"].(bool)
+		return binaryResult(cfg, "image-resize", resp.Header.Get("Content-Type"), resp.Body, save)
+	})
+}
+
+func CreateImageresizeTool(cfg *config.APIConfig) models.Tool {
+	tool := mcp.NewTool("get_image-resize",
+		mcp.WithDescription("Image Resize"),
+		mcp.WithString("image-url", mcp.Required(), mcp.Description("The URL or Base64 encoded Data URL for the source image. You can also upload an image file directly using multipart/form-data")),
+		mcp.WithNumber("width", mcp.Required(), mcp.Description("The width to resize to (in px)")),
+		mcp.WithString("bg-color", mcp.Description("The image background color in hexadecimal notation (e.g. #0000ff). For PNG output the special value of 'transparent' can also be used. For JPG output the default is black (#000000)")),
+		mcp.WithString("format", mcp.Description("The output image format, can be either png or jpg")),
+		mcp.WithNumber("height", mcp.Description("The height to resize to (in px). If you don't set this field then the height will be automatic based on the requested width and image aspect ratio")),
+		mcp.WithString("resize-mode", mcp.Description("The resize mode to use, we support 3 main resizing modes: <ul> <li><b>scale</b><br>Resize to within the width and height specified while preserving aspect ratio. In this mode the width or height will be automatically adjusted to fit the aspect ratio</li> <li><b>pad</b><br>Resize to exactly the width and height specified while preserving aspect ratio and pad any space left over. Any padded space will be filled in with the 'bg-color' value</li> <li><b>crop</b><br>Resize to exactly the width and height specified while preserving aspect ratio and crop any space which fall outside the area. The cropping window is centered on the original image</li> </ul>")),
+		mcp.WithBoolean("save-to-file", mcp.Description("Write the output to the server's configured output directory and return the file path instead of the file content")),
+	)
+
+	return models.Tool{
+		Definition: tool,
+		Handler:    ImageresizeHandler(cfg),
+		Category:   "imaging",
+	}
+}
@@ -0,0 +1,41 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/neutrino-api/mcp-server/client"
+	"github.com/neutrino-api/mcp-server/config"
+	"github.com/neutrino-api/mcp-server/models"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func QrcodeHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return client.RawHandler(cfg, client.Endpoint{
+		Method: http.MethodPost,
+		Path:   "/qr-code",
+		Params: []string{"content", "bg-color", "fg-color", "height", "width"},
+		Accept: "*/*",
+	}, func(args map[string]any, resp *client.Response) *mcp.CallToolResult {
+		save, _ := args["save-to-file"].(bool)
+		return binaryResult(cfg, "qr-code", resp.Header.Get("Content-Type"), resp.Body, save)
+	})
+}
+
+func CreateQrcodeTool(cfg *config.APIConfig) models.Tool {
+	tool := mcp.NewTool("get_qr-code",
+		mcp.WithDescription("QR Code"),
+		mcp.WithString("content", mcp.Required(), mcp.Description("The content to encode into the QR code (e.g. a URL or a phone number)")),
+		mcp.WithString("bg-color", mcp.Description("The QR code background color")),
+		mcp.WithString("fg-color", mcp.Description("The QR code foreground color")),
+		mcp.WithNumber("height", mcp.Description("The height of the QR code (in px)")),
+		mcp.WithNumber("width", mcp.Description("The width of the QR code (in px)")),
+		mcp.WithBoolean("save-to-file", mcp.Description("Write the output to the server's configured output directory and return the file path instead of the file content")),
+	)
+
+	return models.Tool{
+		Definition: tool,
+		Handler:    QrcodeHandler(cfg),
+		Category:   "imaging",
+	}
+}
@@ -2,82 +2,26 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"io"
 	"net/http"
-	"strings"
 
+	"github.com/neutrino-api/mcp-server/client"
 	"github.com/neutrino-api/mcp-server/config"
 	"github.com/neutrino-api/mcp-server/models"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 func HostreputationHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		args, ok := request.Params.Arguments.(map[string]any)
-		if !ok {
-			return mcp.NewToolResultError("Invalid arguments object"), nil
-		}
-		queryParams := make([]string, 0)
-		if val, ok := args["host"]; ok {
-			queryParams = append(queryParams, fmt.Sprintf("host=%v", val))
-		}
-		if val, ok := args["list-rating"]; ok {
-			queryParams = append(queryParams, fmt.Sprintf("list-rating=%v", val))
-		}
-		if val, ok := args["zones"]; ok {
-			queryParams = append(queryParams, fmt.Sprintf("zones=%v", val))
-		}
-		queryString := ""
-		if len(queryParams) > 0 {
-			queryString = "?" + strings.Join(queryParams, "&")
-		}
-		url := fmt.Sprintf("%s/host-reputation%s", cfg.BaseURL, queryString)
-		req, err := http.NewRequest("GET", url, nil)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to create request", err), nil
-		}
-		// Set authentication based on auth type
-		// Fallback to single auth parameter
-		if cfg.APIKey != "" {
-			req.Header.Set("api-key", cfg.APIKey)
-		}
-		req.Header.Set("Accept", "application/json")
-
-		resp, err := http.DefaultClient.Do(req)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Request failed", err), nil
-		}
-		defer resp.Body.Close()
-
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to read response body", err), nil
-		}
-
-		if resp.StatusCode >= 400 {
-			return mcp.NewToolResultError(fmt.Sprintf("API error: %s", body)), nil
-		}
-		// Use properly typed response
-		var result models.HostReputationResponse
-		if err := json.Unmarshal(body, &result); err != nil {
-			// Fallback to raw text if unmarshaling fails
-			return mcp.NewToolResultText(string(body)), nil
-		}
-
-		prettyJSON, err := json.MarshalIndent(result, "", "  ")
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to format JSON", err), nil
-		}
-
-		return mcp.NewToolResultText(string(prettyJSON)), nil
-	}
+	return client.JSONHandler[models.HostReputationResponse](cfg, client.Endpoint{
+		Method: http.MethodGet,
+		Path:   "/host-reputation",
+		Params: []string{"host", "list-rating", "zones"},
+	})
 }
 
 func CreateHostreputationTool(cfg *config.APIConfig) models.Tool {
 	tool := mcp.NewTool("get_host-reputation",
 		mcp.WithDescription("Host Reputation"),
+		mcp.WithOutputSchema[models.HostReputationResponse](),
 		mcp.WithString("host", mcp.Required(), mcp.Description("An IP address, domain name, FQDN or URL. <br>If you supply a domain/URL it will be checked against the URI DNSBL lists")),
 		mcp.WithNumber("list-rating", mcp.Description("Only check lists with this rating or better")),
 		mcp.WithString("zones", mcp.Description("Only check these DNSBL zones/hosts. Multiple zones can be supplied as comma-separated values")),
@@ -86,5 +30,7 @@ func CreateHostreputationTool(cfg *config.APIConfig) models.Tool {
 	return models.Tool{
 		Definition: tool,
 		Handler:    HostreputationHandler(cfg),
+		Category:   "security_and_networking",
+		Cacheable:  true,
 	}
 }
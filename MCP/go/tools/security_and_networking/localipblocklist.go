@@ -0,0 +1,45 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/neutrino-api/mcp-server/client"
+	"github.com/neutrino-api/mcp-server/config"
+	"github.com/neutrino-api/mcp-server/dataset"
+	"github.com/neutrino-api/mcp-server/models"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func LocalIpblocklistHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		blocklist, err := dataset.IPBlocklist.Get()
+		if err != nil {
+			return client.ErrorResult(err), nil
+		}
+		ip, err := request.RequireString("ip")
+		if err != nil {
+			return client.ErrorResult(&client.ToolError{Code: client.CodeInvalidArgument, Message: err.Error()}), nil
+		}
+		addr, err := dataset.ParseIP(ip)
+		if err != nil {
+			return client.ErrorResult(&client.ToolError{Code: client.CodeInvalidArgument, Message: err.Error()}), nil
+		}
+		return client.JSONResult(blocklist.Lookup(addr, request.GetBool("vpn-lookup", false))), nil
+	}
+}
+
+func CreateLocalIpblocklistTool(cfg *config.APIConfig) models.Tool {
+	tool := mcp.NewTool("local_ip-blocklist",
+		mcp.WithDescription("IP Blocklist answered from the downloaded IP blocklist, without calling the API. Matches single addresses and listed CIDR ranges. Sensors are never set"),
+		mcp.WithOutputSchema[models.IPBlocklistResponse](),
+		mcp.WithString("ip", mcp.Required(), mcp.Description("An IPv4 or IPv6 address. Accepts standard IP notation (with or without port number), CIDR notation and IPv6 compressed notation. If multiple IPs are passed using comma-separated values the first one is checked")),
+		mcp.WithBoolean("vpn-lookup", mcp.Description("Include public VPN provider IP addresses. Only available when the server downloads the blocklist with IP_BLOCKLIST_INCLUDE_VPN")),
+	)
+
+	return models.Tool{
+		Definition: tool,
+		Handler:    LocalIpblocklistHandler(cfg),
+		Category:   "security_and_networking",
+		Dataset:    "ip-blocklist",
+	}
+}
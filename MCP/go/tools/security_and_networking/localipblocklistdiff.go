@@ -0,0 +1,68 @@
+package tools
+
+import (
+	"context"
+	"strings"
+
+	"github.com/neutrino-api/mcp-server/client"
+	"github.com/neutrino-api/mcp-server/config"
+	"github.com/neutrino-api/mcp-server/dataset"
+	"github.com/neutrino-api/mcp-server/models"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func LocalIpblocklistdiffHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		dir := dataset.IPBlocklist.Dir()
+		if dir == "" {
+			return client.ErrorResult(&client.ToolError{
+				Code:    client.CodeUpstreamUnavailable,
+				Message: "No blocklist versions are stored, the server needs DATASET_DIR",
+			}), nil
+		}
+		categories, err := dataset.ParseCategories(strings.FieldsFunc(request.GetString("categories", ""), func(r rune) bool {
+			return r == ',' || r == ' '
+		}))
+		if err != nil {
+			return client.ErrorResult(&client.ToolError{Code: client.CodeInvalidArgument, Message: err.Error()}), nil
+		}
+		format := strings.ToLower(request.GetString("format", "json"))
+		if format != "json" && format != "csv" {
+			return client.ErrorResult(&client.ToolError{Code: client.CodeInvalidArgument, Message: "format must be json or csv"}), nil
+		}
+
+		diff, err := dataset.DiffStoredBlocklists(dir, request.GetString("from", ""), request.GetString("to", ""), categories, request.GetBool("include-vpn", false), request.GetInt("limit", 1000))
+		if err != nil {
+			return client.ErrorResult(&client.ToolError{Code: client.CodeInvalidArgument, Message: err.Error()}), nil
+		}
+		if format == "csv" {
+			var b strings.Builder
+			if err := diff.WriteCSV(&b); err != nil {
+				return client.ErrorResult(err), nil
+			}
+			// The structured content still follows the output schema
+			return mcp.NewToolResultStructured(diff, b.String()), nil
+		}
+		return client.JSONResult(diff), nil
+	}
+}
+
+func CreateLocalIpblocklistdiffTool(cfg *config.APIConfig) models.Tool {
+	tool := mcp.NewTool("local_ip-blocklist-diff",
+		mcp.WithDescription("IP Blocklist Diff between two stored versions of the downloaded IP blocklist, without calling the API. Lists the addresses added to and removed from each category, and from the blocklist as a whole (category \"any\"), as the fewest CIDRs covering them. Versions are listed by local_dataset-status"),
+		mcp.WithOutputSchema[dataset.BlocklistDiff](),
+		mcp.WithString("from", mcp.Description("The older version, defaults to the one before the newer version")),
+		mcp.WithString("to", mcp.Description("The newer version, defaults to the newest")),
+		mcp.WithString("categories", mcp.Description("Comma-separated categories to compare, all by default: bot, exploit-bot, hijacked, malware, proxy, spam-bot, spider, spyware, tor, vpn, dshield")),
+		mcp.WithBoolean("include-vpn", mcp.Description("Include addresses only listed as public VPN providers, and the vpn category. Also implied by selecting the vpn category")),
+		mcp.WithString("format", mcp.Description("The output format, json or csv. CSV replaces the JSON text, with category, change and cidr columns"), mcp.Enum("json", "csv")),
+		mcp.WithNumber("limit", mcp.Description("The most CIDRs listed per category and change, 0 for no limit. Defaults to 1000")),
+	)
+
+	return models.Tool{
+		Definition: tool,
+		Handler:    LocalIpblocklistdiffHandler(cfg),
+		Category:   "security_and_networking",
+		Dataset:    "ip-blocklist",
+	}
+}
@@ -0,0 +1,99 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/neutrino-api/mcp-server/client"
+	"github.com/neutrino-api/mcp-server/config"
+	"github.com/neutrino-api/mcp-server/dataset"
+	"github.com/neutrino-api/mcp-server/models"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func LocalIpblocklistexportHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		blocklist, version, err := dataset.IPBlocklist.Current()
+		if err != nil {
+			return client.ErrorResult(err), nil
+		}
+		format, err := request.RequireString("format")
+		if err != nil {
+			return client.ErrorResult(&client.ToolError{Code: client.CodeInvalidArgument, Message: err.Error()}), nil
+		}
+		categories, err := dataset.ParseCategories(strings.FieldsFunc(request.GetString("categories", ""), func(r rune) bool {
+			return r == ',' || r == ' '
+		}))
+		if err != nil {
+			return client.ErrorResult(&client.ToolError{Code: client.CodeInvalidArgument, Message: err.Error()}), nil
+		}
+		opts := dataset.ExportOptions{
+			Format:     strings.ToLower(format),
+			Family:     strings.ToLower(request.GetString("family", "")),
+			Categories: categories,
+			VPN:        request.GetBool("include-vpn", false),
+			Name:       request.GetString("name", ""),
+			Table:      request.GetString("table", ""),
+			Version:    version,
+		}
+
+		var b strings.Builder
+		count, err := dataset.ExportBlocklist(&b, blocklist, opts)
+		if err != nil {
+			return client.ErrorResult(&client.ToolError{Code: client.CodeInvalidArgument, Message: err.Error()}), nil
+		}
+		name := "ip-blocklist-" + version
+		if opts.Family != "" {
+			name += "-" + opts.Family
+		}
+		ext := dataset.ExportExtension(opts.Format)
+
+		if request.GetBool("save-to-file", false) {
+			if cfg.OutputDir == "" {
+				return client.ErrorResult(&client.ToolError{Code: client.CodeInvalidArgument, Message: "save-to-file requested but no output directory is configured (set OUTPUT_DIR)"}), nil
+			}
+			f, err := os.CreateTemp(cfg.OutputDir, name+"-*"+ext)
+			if err != nil {
+				return client.ErrorResult(&client.ToolError{Code: client.CodeInternal, Message: "Failed to create output file: " + err.Error()}), nil
+			}
+			_, err = f.WriteString(b.String())
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(f.Name())
+				return client.ErrorResult(&client.ToolError{Code: client.CodeInternal, Message: "Failed to write output file: " + err.Error()}), nil
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("Saved %d CIDRs (%s) to %s", count, opts.Format, f.Name())), nil
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{mcp.NewEmbeddedResource(mcp.TextResourceContents{
+				URI:      fmt.Sprintf("neutrino://local_ip-blocklist-export/%s%s", name, ext),
+				MIMEType: "text/plain",
+				Text:     b.String(),
+			})},
+		}, nil
+	}
+}
+
+func CreateLocalIpblocklistexportTool(cfg *config.APIConfig) models.Tool {
+	tool := mcp.NewTool("local_ip-blocklist-export",
+		mcp.WithDescription("IP Blocklist Export of the downloaded IP blocklist as firewall configuration, without calling the API. Listed addresses are aggregated into the fewest CIDRs covering them and rendered as nftables interval sets (for nft -f), ipset hash:net sets (for ipset restore), an iptables chain dropping them (for iptables-restore --noflush) or a plain CIDR list. The content is returned as a resource, or written to the server's output directory"),
+		mcp.WithString("format", mcp.Required(), mcp.Description("The export format"), mcp.Enum(dataset.ExportFormats...)),
+		mcp.WithString("family", mcp.Description("Only export IPv4 or IPv6 addresses. Both are exported by default, as separate sets suffixed -v4 and -v6. Required for iptables, whose IPv6 rules are loaded by ip6tables-restore"), mcp.Enum("ipv4", "ipv6")),
+		mcp.WithString("categories", mcp.Description("Comma-separated categories, only addresses listed in any of them are exported. All listed addresses by default: bot, exploit-bot, hijacked, malware, proxy, spam-bot, spider, spyware, tor, vpn, dshield")),
+		mcp.WithBoolean("include-vpn", mcp.Description("Include addresses only listed as public VPN providers. Only available when the server downloads the blocklist with IP_BLOCKLIST_INCLUDE_VPN")),
+		mcp.WithString("name", mcp.Description("The set or chain name, defaults to neutrino-blocklist")),
+		mcp.WithString("table", mcp.Description("The nftables table holding the sets, defaults to filter")),
+		mcp.WithBoolean("save-to-file", mcp.Description("Write the output to the server's configured output directory and return the file path instead of the file content")),
+	)
+
+	return models.Tool{
+		Definition: tool,
+		Handler:    LocalIpblocklistexportHandler(cfg),
+		Category:   "security_and_networking",
+		Dataset:    "ip-blocklist",
+	}
+}
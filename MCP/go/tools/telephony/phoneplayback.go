@@ -0,0 +1,36 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/neutrino-api/mcp-server/client"
+	"github.com/neutrino-api/mcp-server/config"
+	"github.com/neutrino-api/mcp-server/models"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func PhoneplaybackHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return client.JSONHandler[models.PhonePlaybackResponse](cfg, client.Endpoint{
+		Method: http.MethodPost,
+		Path:   "/phone-playback",
+		Params: []string{"audio-url", "number", "limit", "limit-ttl"},
+	})
+}
+
+func CreatePhoneplaybackTool(cfg *config.APIConfig) models.Tool {
+	tool := mcp.NewTool("get_phone-playback",
+		mcp.WithDescription("Phone Playback"),
+		mcp.WithOutputSchema[models.PhonePlaybackResponse](),
+		mcp.WithString("audio-url", mcp.Required(), mcp.Description("A URL to a valid audio file. Accepted audio formats are: <ul> <li>MP3</li> <li>WAV</li> <li>OGG</li> </ul>You can use the following MP3 URL for testing: <br>https://www.neutrinoapi.com/test-files/test1.mp3")),
+		mcp.WithString("number", mcp.Required(), mcp.Description("The phone number to call. Must be in valid international format")),
+		mcp.WithNumber("limit", mcp.Description("Limit the total number of calls allowed to the supplied phone number, if the limit is reached within the TTL then error code 14 will be returned")),
+		mcp.WithNumber("limit-ttl", mcp.Description("Set the TTL in number of days that the 'limit' option will remember a phone number (the default is 1 day and the maximum is 365 days)")),
+	)
+
+	return models.Tool{
+		Definition: tool,
+		Handler:    PhoneplaybackHandler(cfg),
+		Category:   "telephony",
+	}
+}
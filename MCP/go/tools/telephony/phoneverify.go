@@ -0,0 +1,40 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/neutrino-api/mcp-server/client"
+	"github.com/neutrino-api/mcp-server/config"
+	"github.com/neutrino-api/mcp-server/models"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func PhoneverifyHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return client.JSONHandler[models.PhoneVerifyResponse](cfg, client.Endpoint{
+		Method: http.MethodPost,
+		Path:   "/phone-verify",
+		Params: []string{"number", "code-length", "country-code", "language-code", "limit", "limit-ttl", "playback-delay", "security-code"},
+	})
+}
+
+func CreatePhoneverifyTool(cfg *config.APIConfig) models.Tool {
+	tool := mcp.NewTool("get_phone-verify",
+		mcp.WithDescription("Phone Verify"),
+		mcp.WithOutputSchema[models.PhoneVerifyResponse](),
+		mcp.WithString("number", mcp.Required(), mcp.Description("The phone number to send the verification code to")),
+		mcp.WithNumber("code-length", mcp.Description("The number of digits to use in the security code (between 4 and 12)")),
+		mcp.WithString("country-code", mcp.Description("ISO 2-letter country code, assume numbers are based in this country. <br>If not set numbers are assumed to be in international format (with or without the leading + sign)")),
+		mcp.WithString("language-code", mcp.Description("The language to playback the verification code in, available languages are: <ul> <li>de - German</li> <li>en - English</li> <li>es - Spanish</li> <li>fr - French</li> <li>it - Italian</li> <li>pt - Portuguese</li> <li>ru - Russian</li> </ul>")),
+		mcp.WithNumber("limit", mcp.Description("Limit the total number of calls allowed to the supplied phone number, if the limit is reached within the TTL then error code 14 will be returned")),
+		mcp.WithNumber("limit-ttl", mcp.Description("Set the TTL in number of days that the 'limit' option will remember a phone number (the default is 1 day and the maximum is 365 days)")),
+		mcp.WithNumber("playback-delay", mcp.Description("The delay in milliseconds between the playback of each security code")),
+		mcp.WithNumber("security-code", mcp.Description("Pass in your own security code. This is useful if you have implemented TOTP or similar 2FA methods. If not set then we will generate a secure random code")),
+	)
+
+	return models.Tool{
+		Definition: tool,
+		Handler:    PhoneverifyHandler(cfg),
+		Category:   "telephony",
+	}
+}
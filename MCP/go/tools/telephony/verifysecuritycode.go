@@ -2,85 +2,35 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"io"
 	"net/http"
-	"strings"
 
+	"github.com/neutrino-api/mcp-server/client"
 	"github.com/neutrino-api/mcp-server/config"
 	"github.com/neutrino-api/mcp-server/models"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 func VerifysecuritycodeHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		args, ok := request.Params.Arguments.(map[string]any)
-		if !ok {
-			return mcp.NewToolResultError("Invalid arguments object"), nil
-		}
-		queryParams := make([]string, 0)
-		if val, ok := args["security-code"]; ok {
-			queryParams = append(queryParams, fmt.Sprintf("security-code=%v", val))
-		}
-		if val, ok := args["limit-by"]; ok {
-			queryParams = append(queryParams, fmt.Sprintf("limit-by=%v", val))
-		}
-		queryString := ""
-		if len(queryParams) > 0 {
-			queryString = "?" + strings.Join(queryParams, "&")
-		}
-		url := fmt.Sprintf("%s/verify-security-code%s", cfg.BaseURL, queryString)
-		req, err := http.NewRequest("GET", url, nil)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to create request", err), nil
-		}
-		// Set authentication based on auth type
-		// Fallback to single auth parameter
-		if cfg.APIKey != "" {
-			req.Header.Set("user-id", cfg.APIKey)
-		}
-		req.Header.Set("Accept", "application/json")
-
-		resp, err := http.DefaultClient.Do(req)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Request failed", err), nil
-		}
-		defer resp.Body.Close()
-
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to read response body", err), nil
-		}
-
-		if resp.StatusCode >= 400 {
-			return mcp.NewToolResultError(fmt.Sprintf("API error: %s", body)), nil
-		}
-		// Use properly typed response
-		var result models.VerifySecurityCodeResponse
-		if err := json.Unmarshal(body, &result); err != nil {
-			// Fallback to raw text if unmarshaling fails
-			return mcp.NewToolResultText(string(body)), nil
-		}
-
-		prettyJSON, err := json.MarshalIndent(result, "", "  ")
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to format JSON", err), nil
-		}
-
-		return mcp.NewToolResultText(string(prettyJSON)), nil
-	}
+	return client.JSONHandler[models.VerifySecurityCodeResponse](cfg, client.Endpoint{
+		Method: http.MethodGet,
+		Path:   "/verify-security-code",
+		Params: []string{"security-code", "limit-by"},
+	})
 }
 
 func CreateVerifysecuritycodeTool(cfg *config.APIConfig) models.Tool {
 	tool := mcp.NewTool("get_verify-security-code",
 		mcp.WithDescription("Verify Security Code"),
+		mcp.WithOutputSchema[models.VerifySecurityCodeResponse](),
 		mcp.WithString("security-code", mcp.Required(), mcp.Description("The security code to verify")),
 		mcp.WithString("limit-by", mcp.Description("If set then enable additional brute-force protection by limiting the number of attempts by the supplied value. This can be set to any unique identifier you would like to limit by, for example a hash of the users email, phone number or IP address. Requests to this API will be ignored after approximately 10 failed verification attempts")),
 	)
 
+	// Not Cacheable: a code can only be checked once, so every call must
+	// reach the API
 	return models.Tool{
 		Definition: tool,
 		Handler:    VerifysecuritycodeHandler(cfg),
+		Category:   "telephony",
 	}
 }
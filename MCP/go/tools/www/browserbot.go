@@ -0,0 +1,46 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/neutrino-api/mcp-server/client"
+	"github.com/neutrino-api/mcp-server/config"
+	"github.com/neutrino-api/mcp-server/models"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func BrowserbotHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return client.JSONHandler(cfg, client.Endpoint{
+		Method: http.MethodPost,
+		Path:   "/browser-bot",
+		Params: []string{"url", "timeout", "delay", "selector", "exec", "user-agent", "ignore-certificate-errors"},
+	}, func(args map[string]any, result *models.BrowserBotResponse) {
+		// The raw page content can be megabytes of HTML, callers that only
+		// need selectors or exec results can leave it out
+		if omit, _ := args["omit-content"].(bool); omit {
+			result.Content = ""
+		}
+	})
+}
+
+func CreateBrowserbotTool(cfg *config.APIConfig) models.Tool {
+	tool := mcp.NewTool("get_browser-bot",
+		mcp.WithDescription("Browser Bot"),
+		mcp.WithOutputSchema[models.BrowserBotResponse](),
+		mcp.WithString("url", mcp.Required(), mcp.Description("The URL to load")),
+		mcp.WithNumber("timeout", mcp.Description("Timeout in seconds. Give up if still trying to load the page after this number of seconds")),
+		mcp.WithNumber("delay", mcp.Description("Delay in seconds to wait before capturing any page data, executing selectors or JavaScript")),
+		mcp.WithString("selector", mcp.Description("Extract content from the page DOM using this selector. Commonly known as a CSS selector, you can find a good reference <a href=\"https://www.w3schools.com/cssref/css_selectors.asp\">here</a>")),
+		mcp.WithArray("exec", mcp.WithStringItems(), mcp.Description("Execute JavaScript on the website. Each array entry is a separate statement. If a statement returns any value it will be returned in the 'exec-results' response. You can also use the following specially defined user interaction functions: <br> <br> <div> sleep(seconds); Just wait/sleep for the specified number of seconds. <br>click('selector'); Click on the first element matching the given selector. <br>focus('selector'); Focus on the first element matching the given selector. <br>keys('characters'); Send the specified keyboard characters. Use click() or focus() first to send keys to a specific element. <br>enter(); Send the Enter key. <br>tab(); Send the Tab key. <br> </div>")),
+		mcp.WithString("user-agent", mcp.Description("Override the browsers default user-agent string with this one")),
+		mcp.WithBoolean("ignore-certificate-errors", mcp.Description("Ignore any TLS/SSL certificate errors and load the page anyway")),
+		mcp.WithBoolean("omit-content", mcp.Description("Leave the raw page content out of the result. Useful when only the selector elements or exec results are needed")),
+	)
+
+	return models.Tool{
+		Definition: tool,
+		Handler:    BrowserbotHandler(cfg),
+		Category:   "www",
+	}
+}
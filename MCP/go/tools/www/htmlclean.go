@@ -0,0 +1,34 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/neutrino-api/mcp-server/client"
+	"github.com/neutrino-api/mcp-server/config"
+	"github.com/neutrino-api/mcp-server/models"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func HtmlcleanHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return client.TextHandler(cfg, client.Endpoint{
+		Method: http.MethodPost,
+		Path:   "/html-clean",
+		Params: []string{"content", "output-type"},
+		Accept: "text/html",
+	})
+}
+
+func CreateHtmlcleanTool(cfg *config.APIConfig) models.Tool {
+	tool := mcp.NewTool("get_html-clean",
+		mcp.WithDescription("HTML Clean"),
+		mcp.WithString("content", mcp.Required(), mcp.Description("The HTML content. This can be either a URL to load from, a file upload (multipart/form-data) or an HTML content string")),
+		mcp.WithString("output-type", mcp.Required(), mcp.Description("The level of sanitization, possible values are: <br><b>plain-text</b>: reduce the content to plain text only (no HTML tags at all) <br><b>simple-text</b>: allow only very basic text formatting tags like b, em, i, strong, u <br><b>basic-html</b>: allow advanced text formatting and hyper links <br><b>basic-html-with-images</b>: same as basic html but also allows image tags <br><b>advanced-html</b>: same as basic html with images but also allows many more common HTML tags like table, ul, dl, pre <br>")),
+	)
+
+	return models.Tool{
+		Definition: tool,
+		Handler:    HtmlcleanHandler(cfg),
+		Category:   "www",
+	}
+}